@@ -16,6 +16,8 @@
 package backend
 
 import (
+	"context"
+	"errors"
 	"io"
 	"os"
 
@@ -51,7 +53,7 @@ func (id ID) String() string {
 // NewProverConfig returns a default ProverConfig with given prover options opts
 // applied.
 func NewProverConfig(opts ...ProverOption) (ProverConfig, error) {
-	opt := ProverConfig{LoggerOut: os.Stdout, HintFunctions: hint.GetAll()}
+	opt := ProverConfig{LoggerOut: os.Stdout, HintFunctions: hint.GetAll(), Ctx: context.Background()}
 	for _, option := range opts {
 		if err := option(&opt); err != nil {
 			return ProverConfig{}, err
@@ -67,9 +69,23 @@ type ProverOption func(*ProverConfig) error
 
 // ProverConfig is the configuration for the prover with the options applied.
 type ProverConfig struct {
-	Force         bool            // defaults to false
-	HintFunctions []hint.Function // defaults to all built-in hint functions
-	LoggerOut     io.Writer       // defaults to os.Stdout
+	Force           bool            // defaults to false
+	HintFunctions   []hint.Function // defaults to all built-in hint functions
+	LoggerOut       io.Writer       // defaults to os.Stdout
+	Ctx             context.Context // defaults to context.Background()
+	Observer        ProverObserver  // defaults to nil
+	NbTasks         int             // defaults to runtime.NumCPU()
+	CheckpointPath  string          // defaults to "", no checkpoint is saved
+	NoZeroKnowledge bool            // defaults to false
+}
+
+// Context returns the context set with WithContext, or context.Background() if
+// none was set
+func (cfg ProverConfig) Context() context.Context {
+	if cfg.Ctx == nil {
+		return context.Background()
+	}
+	return cfg.Ctx
 }
 
 // IgnoreSolverError is a prover option that indicates that the Prove algorithm
@@ -102,3 +118,95 @@ func WithOutput(w io.Writer) ProverOption {
 		return nil
 	}
 }
+
+// WithContext is a prover option that sets the context of the prover. It is checked
+// between the phases of Prove (solver, FFTs, multi-exponentiations), which returns
+// ctx.Err() once ctx is done.
+func WithContext(ctx context.Context) ProverOption {
+	return func(opt *ProverConfig) error {
+		if ctx == nil {
+			return errors.New("nil context")
+		}
+		opt.Ctx = ctx
+		return nil
+	}
+}
+
+// WithNbTasks is a prover option that sets the maximum number of concurrent tasks of the
+// solver, the multi-exponentiations and the parallel loops of Prove and IsSolved.
+//
+// Note that the FFTs and the KZG commitments and openings of gnark-crypto use
+// runtime.NumCPU() tasks: gnark-crypto v0.6.1 doesn't take a number of tasks for these.
+//
+// TODO pass NbTasks to the FFTs and to KZG when gnark-crypto takes a number of tasks
+func WithNbTasks(nbTasks int) ProverOption {
+	return func(opt *ProverConfig) error {
+		if nbTasks < 1 {
+			return errors.New("the number of tasks must be positive")
+		}
+		opt.NbTasks = nbTasks
+		return nil
+	}
+}
+
+// WithoutZeroKnowledge is a prover option that disables the blinding of the PlonK proofs, for
+// witnesses that are not secret. Proving is faster, but the proofs reveal information about the
+// witness. The proving key must have been setup with WithSetupWithoutZeroKnowledge, which marks
+// its VerifyingKey as such.
+//
+// Groth16 proofs are always zero-knowledge: the Groth16 Prove returns an error with this option.
+func WithoutZeroKnowledge() ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.NoZeroKnowledge = true
+		return nil
+	}
+}
+
+// NewSetupConfig returns a default SetupConfig with given setup options opts applied.
+func NewSetupConfig(opts ...SetupOption) (SetupConfig, error) {
+	var opt SetupConfig
+	for _, option := range opts {
+		if err := option(&opt); err != nil {
+			return SetupConfig{}, err
+		}
+	}
+	return opt, nil
+}
+
+// SetupOption defines option for altering the behaviour of Setup. See the descriptions
+// of functions returning instances of this type for implemented options.
+type SetupOption func(*SetupConfig) error
+
+// SetupConfig is the configuration for Setup with the options applied.
+type SetupConfig struct {
+	NbTasks         int  // defaults to runtime.NumCPU()
+	NoZeroKnowledge bool // defaults to false
+}
+
+// WithSetupNbTasks is a setup option that sets the maximum number of concurrent tasks of
+// the scalar multiplications of the Groth16 Setup and of the multi-exponentiations of the
+// PlonK Setup.
+//
+// Note that the FFTs of gnark-crypto use runtime.NumCPU() tasks: gnark-crypto v0.6.1 doesn't
+// take a number of tasks for these.
+//
+// TODO pass NbTasks to the FFTs when gnark-crypto takes a number of tasks
+func WithSetupNbTasks(nbTasks int) SetupOption {
+	return func(opt *SetupConfig) error {
+		if nbTasks < 1 {
+			return errors.New("the number of tasks must be positive")
+		}
+		opt.NbTasks = nbTasks
+		return nil
+	}
+}
+
+// WithSetupWithoutZeroKnowledge is a setup option that sets up PlonK keys for proofs that are not
+// blinded (see WithoutZeroKnowledge). The flag is recorded in the VerifyingKey, which then only
+// verifies such proofs. The KZG SRS needs 3 points less than plonk.SRSSize.
+func WithSetupWithoutZeroKnowledge() SetupOption {
+	return func(opt *SetupConfig) error {
+		opt.NoZeroKnowledge = true
+		return nil
+	}
+}
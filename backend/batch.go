@@ -0,0 +1,67 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// BatchError is returned by BatchProve when some witnesses of the batch could not be proven.
+// The proofs of the other witnesses are returned along with it.
+type BatchError struct {
+	// Errs[i] is the error returned when proving the i-th witness, or nil if it was proven
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	nbFailed, first := 0, -1
+	for i, err := range e.Errs {
+		if err != nil {
+			if first == -1 {
+				first = i
+			}
+			nbFailed++
+		}
+	}
+	if nbFailed == 0 {
+		return "batch proving succeeded"
+	}
+	return fmt.Sprintf("proving failed for %d of %d witnesses, first (witness %d): %v", nbFailed, len(e.Errs), first, e.Errs[first])
+}
+
+// BatchTasks splits the tasks of cfg among the witnesses of a batch of nbProofs proofs, until
+// their multi-exponentiations. It returns the number of witnesses to process concurrently, and
+// the number of tasks each of them uses.
+//
+// Two witnesses are processed concurrently: while one is in the (partly sequential) solver, the
+// other runs its FFTs.
+func (cfg ProverConfig) BatchTasks(nbProofs int) (nbParallel, nbTasks int) {
+	nbTasks = cfg.NbTasks
+	if nbTasks <= 0 {
+		nbTasks = runtime.NumCPU()
+	}
+	nbParallel = 2
+	if nbProofs < nbParallel {
+		nbParallel = nbProofs
+	}
+	if nbTasks < nbParallel {
+		nbParallel = nbTasks
+	}
+	if nbParallel < 1 {
+		nbParallel = 1
+	}
+	return nbParallel, nbTasks / nbParallel
+}
@@ -0,0 +1,87 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+)
+
+// ErrCheckpointMismatch is returned when resuming a proof from a checkpoint that was not saved
+// for the same proving key and witness
+var ErrCheckpointMismatch = errors.New("checkpoint doesn't match the proving key and witness")
+
+// WithCheckpoint is a prover option that makes Prove save its intermediate state to path at
+// the end of each round, so that a proof interrupted by a crash can be resumed from its last
+// completed round with groth16.Resume or plonk.Resume.
+//
+// The file is replaced atomically and is left in place once the proof completes. It holds
+// values derived from the secret witness, and should be protected accordingly.
+func WithCheckpoint(path string) ProverOption {
+	return func(opt *ProverConfig) error {
+		if path == "" {
+			return errors.New("empty checkpoint path")
+		}
+		opt.CheckpointPath = path
+		return nil
+	}
+}
+
+// SaveCheckpoint calls write to save a checkpoint to the path set with WithCheckpoint, if any.
+// The checkpoint is written to a temporary file which then replaces the previous one, so that
+// a crash while writing doesn't corrupt it.
+func (cfg ProverConfig) SaveCheckpoint(write func(w io.Writer) error) error {
+	if cfg.CheckpointPath == "" {
+		return nil
+	}
+	end := cfg.StartPhase("checkpoint")
+
+	tmp := cfg.CheckpointPath + ".tmp"
+	if err := writeSync(tmp, write); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, cfg.CheckpointPath); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	end(0)
+	return nil
+}
+
+// writeSync calls write to write the file at path, and flushes it to disk
+func writeSync(path string, write func(w io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(f)
+	if err := write(bw); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
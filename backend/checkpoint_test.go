@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveCheckpoint(t *testing.T) {
+	assert := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "proof.checkpoint")
+	cfg, err := NewProverConfig(WithCheckpoint(path))
+	assert.NoError(err)
+
+	assert.NoError(cfg.SaveCheckpoint(func(w io.Writer) error {
+		_, err := w.Write([]byte("round 1"))
+		return err
+	}))
+	data, err := os.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("round 1", string(data))
+
+	// a failed write keeps the previous checkpoint, and leaves no temporary file
+	errWrite := errors.New("write failed")
+	err = cfg.SaveCheckpoint(func(w io.Writer) error {
+		if _, err := w.Write([]byte("round 2")); err != nil {
+			return err
+		}
+		return errWrite
+	})
+	assert.ErrorIs(err, errWrite)
+	data, err = os.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("round 1", string(data))
+	_, err = os.Stat(path + ".tmp")
+	assert.True(os.IsNotExist(err))
+}
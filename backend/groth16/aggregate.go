@@ -0,0 +1,455 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+// The aggregation (https://eprint.iacr.org/2021/529, SnarkPack) replaces the
+// verification of n proofs for the same VerifyingKey by the verification of a single
+// AggregateProof, of size O(log n). The verifier still reads the public inputs of the
+// proofs, but computes O(1) pairings instead of O(n).
+//
+// It needs an AggregationSRS, built from two independent powers of tau ceremonies with
+// SetupAggregationFromPhase1 (or from random secrets with SetupAggregation, for tests).
+// The same AggregationSRS can be used for all the circuits of the curve.
+
+var errAggregationCurveMismatch = errors.New("aggregation objects are defined on different curves")
+
+// AggregationSRS is the structured reference string of the aggregation
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type AggregationSRS interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+}
+
+// AggregateProof is the aggregation of proofs for the same VerifyingKey
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type AggregateProof interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+}
+
+// SetupAggregation returns an AggregationSRS on the curve for up to size proofs, size
+// must be a power of two
+//
+// /!\ warning /!\: the secrets of the SRS are sampled at random and discarded. In
+// production, use SetupAggregationFromPhase1.
+func SetupAggregation(curveID ecc.ID, size int) (AggregationSRS, error) {
+	// the secrets are reduced modulo the order of the scalar field
+	var a, b big.Int
+	buf := make([]byte, 64)
+	for _, s := range []*big.Int{&a, &b} {
+		if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+			return nil, err
+		}
+		s.SetBytes(buf)
+	}
+
+	switch curveID {
+	case ecc.BN254:
+		srs, err := groth16_bn254.NewAggregationSRS(size, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BLS12_377:
+		srs, err := groth16_bls12377.NewAggregationSRS(size, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BLS12_381:
+		srs, err := groth16_bls12381.NewAggregationSRS(size, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BW6_761:
+		srs, err := groth16_bw6761.NewAggregationSRS(size, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BLS24_315:
+		srs, err := groth16_bls24315.NewAggregationSRS(size, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BW6_633:
+		srs, err := groth16_bw6633.NewAggregationSRS(size, &a, &b)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	default:
+		panic("not implemented")
+	}
+}
+
+// SetupAggregationFromPhase1 returns an AggregationSRS for up to size proofs from the
+// final states of two independent powers of tau ceremonies (see InitPhase1). size must
+// be a power of two, and the ceremonies must support circuits of size constraints.
+//
+// The ceremonies are not verified, see VerifyPhase1.
+func SetupAggregationFromPhase1(p1, p2 Phase1, size int) (AggregationSRS, error) {
+	if p1.CurveID() != p2.CurveID() {
+		return nil, ErrCurveMismatch
+	}
+	switch _p1 := p1.(type) {
+	case *groth16_bn254.Phase1:
+		srs, err := groth16_bn254.NewAggregationSRSFromPhase1(_p1, p2.(*groth16_bn254.Phase1), size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bls12377.Phase1:
+		srs, err := groth16_bls12377.NewAggregationSRSFromPhase1(_p1, p2.(*groth16_bls12377.Phase1), size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bls12381.Phase1:
+		srs, err := groth16_bls12381.NewAggregationSRSFromPhase1(_p1, p2.(*groth16_bls12381.Phase1), size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bw6761.Phase1:
+		srs, err := groth16_bw6761.NewAggregationSRSFromPhase1(_p1, p2.(*groth16_bw6761.Phase1), size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bls24315.Phase1:
+		srs, err := groth16_bls24315.NewAggregationSRSFromPhase1(_p1, p2.(*groth16_bls24315.Phase1), size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bw6633.Phase1:
+		srs, err := groth16_bw6633.NewAggregationSRSFromPhase1(_p1, p2.(*groth16_bw6633.Phase1), size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	default:
+		panic("unrecognized Phase1 curve type")
+	}
+}
+
+// NewAggregationSRS instantiates a curve-typed AggregationSRS and returns an interface
+// This function exists for serialization purposes
+func NewAggregationSRS(curveID ecc.ID) AggregationSRS {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.AggregationSRS{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.AggregationSRS{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.AggregationSRS{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.AggregationSRS{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.AggregationSRS{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.AggregationSRS{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewAggregateProof instantiates a curve-typed AggregateProof and returns an interface
+// This function exists for serialization purposes
+func NewAggregateProof(curveID ecc.ID) AggregateProof {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.AggregateProof{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.AggregateProof{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.AggregateProof{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.AggregateProof{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.AggregateProof{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.AggregateProof{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// Aggregate returns the aggregation of proofs for vk, with the matching public
+// witnesses. Any number of proofs can be aggregated, at most the size of the srs: they
+// are padded to the next power of two by repeating the last one.
+//
+// The proofs are not verified: the aggregation of invalid proofs doesn't verify.
+func Aggregate(srs AggregationSRS, vk VerifyingKey, proofs []Proof, publicWitnesses []*witness.Witness) (AggregateProof, error) {
+	if srs.CurveID() != vk.CurveID() {
+		return nil, errAggregationCurveMismatch
+	}
+	switch _srs := srs.(type) {
+	case *groth16_bn254.AggregationSRS:
+		_proofs := make([]*groth16_bn254.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bn254.Proof); !ok {
+				return nil, errAggregationCurveMismatch
+			}
+		}
+		w, err := publicWitnessesbn254(publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := groth16_bn254.Aggregate(_srs, vk.(*groth16_bn254.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	case *groth16_bls12377.AggregationSRS:
+		_proofs := make([]*groth16_bls12377.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bls12377.Proof); !ok {
+				return nil, errAggregationCurveMismatch
+			}
+		}
+		w, err := publicWitnessesbls12377(publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := groth16_bls12377.Aggregate(_srs, vk.(*groth16_bls12377.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	case *groth16_bls12381.AggregationSRS:
+		_proofs := make([]*groth16_bls12381.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bls12381.Proof); !ok {
+				return nil, errAggregationCurveMismatch
+			}
+		}
+		w, err := publicWitnessesbls12381(publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := groth16_bls12381.Aggregate(_srs, vk.(*groth16_bls12381.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	case *groth16_bw6761.AggregationSRS:
+		_proofs := make([]*groth16_bw6761.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bw6761.Proof); !ok {
+				return nil, errAggregationCurveMismatch
+			}
+		}
+		w, err := publicWitnessesbw6761(publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := groth16_bw6761.Aggregate(_srs, vk.(*groth16_bw6761.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	case *groth16_bls24315.AggregationSRS:
+		_proofs := make([]*groth16_bls24315.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bls24315.Proof); !ok {
+				return nil, errAggregationCurveMismatch
+			}
+		}
+		w, err := publicWitnessesbls24315(publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := groth16_bls24315.Aggregate(_srs, vk.(*groth16_bls24315.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	case *groth16_bw6633.AggregationSRS:
+		_proofs := make([]*groth16_bw6633.Proof, len(proofs))
+		for i := 0; i < len(proofs); i++ {
+			var ok bool
+			if _proofs[i], ok = proofs[i].(*groth16_bw6633.Proof); !ok {
+				return nil, errAggregationCurveMismatch
+			}
+		}
+		w, err := publicWitnessesbw6633(publicWitnesses)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := groth16_bw6633.Aggregate(_srs, vk.(*groth16_bw6633.VerifyingKey), _proofs, w)
+		if err != nil {
+			return nil, err
+		}
+		return proof, nil
+	default:
+		panic("unrecognized AggregationSRS curve type")
+	}
+}
+
+// VerifyAggregate verifies an aggregation of proofs for vk, with the public witnesses of
+// the aggregated proofs, in order
+func VerifyAggregate(srs AggregationSRS, vk VerifyingKey, proof AggregateProof, publicWitnesses []*witness.Witness) error {
+	if srs.CurveID() != vk.CurveID() || srs.CurveID() != proof.CurveID() {
+		return errAggregationCurveMismatch
+	}
+	switch _srs := srs.(type) {
+	case *groth16_bn254.AggregationSRS:
+		w, err := publicWitnessesbn254(publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bn254.VerifyAggregate(_srs, vk.(*groth16_bn254.VerifyingKey), proof.(*groth16_bn254.AggregateProof), w)
+	case *groth16_bls12377.AggregationSRS:
+		w, err := publicWitnessesbls12377(publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls12377.VerifyAggregate(_srs, vk.(*groth16_bls12377.VerifyingKey), proof.(*groth16_bls12377.AggregateProof), w)
+	case *groth16_bls12381.AggregationSRS:
+		w, err := publicWitnessesbls12381(publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls12381.VerifyAggregate(_srs, vk.(*groth16_bls12381.VerifyingKey), proof.(*groth16_bls12381.AggregateProof), w)
+	case *groth16_bw6761.AggregationSRS:
+		w, err := publicWitnessesbw6761(publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bw6761.VerifyAggregate(_srs, vk.(*groth16_bw6761.VerifyingKey), proof.(*groth16_bw6761.AggregateProof), w)
+	case *groth16_bls24315.AggregationSRS:
+		w, err := publicWitnessesbls24315(publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls24315.VerifyAggregate(_srs, vk.(*groth16_bls24315.VerifyingKey), proof.(*groth16_bls24315.AggregateProof), w)
+	case *groth16_bw6633.AggregationSRS:
+		w, err := publicWitnessesbw6633(publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bw6633.VerifyAggregate(_srs, vk.(*groth16_bw6633.VerifyingKey), proof.(*groth16_bw6633.AggregateProof), w)
+	default:
+		panic("unrecognized AggregationSRS curve type")
+	}
+}
+
+func publicWitnessesbn254(publicWitnesses []*witness.Witness) ([]witness_bn254.Witness, error) {
+	res := make([]witness_bn254.Witness, len(publicWitnesses))
+	for i := 0; i < len(publicWitnesses); i++ {
+		w, ok := publicWitnesses[i].Vector.(*witness_bn254.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = *w
+	}
+	return res, nil
+}
+
+func publicWitnessesbls12377(publicWitnesses []*witness.Witness) ([]witness_bls12377.Witness, error) {
+	res := make([]witness_bls12377.Witness, len(publicWitnesses))
+	for i := 0; i < len(publicWitnesses); i++ {
+		w, ok := publicWitnesses[i].Vector.(*witness_bls12377.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = *w
+	}
+	return res, nil
+}
+
+func publicWitnessesbls12381(publicWitnesses []*witness.Witness) ([]witness_bls12381.Witness, error) {
+	res := make([]witness_bls12381.Witness, len(publicWitnesses))
+	for i := 0; i < len(publicWitnesses); i++ {
+		w, ok := publicWitnesses[i].Vector.(*witness_bls12381.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = *w
+	}
+	return res, nil
+}
+
+func publicWitnessesbw6761(publicWitnesses []*witness.Witness) ([]witness_bw6761.Witness, error) {
+	res := make([]witness_bw6761.Witness, len(publicWitnesses))
+	for i := 0; i < len(publicWitnesses); i++ {
+		w, ok := publicWitnesses[i].Vector.(*witness_bw6761.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = *w
+	}
+	return res, nil
+}
+
+func publicWitnessesbls24315(publicWitnesses []*witness.Witness) ([]witness_bls24315.Witness, error) {
+	res := make([]witness_bls24315.Witness, len(publicWitnesses))
+	for i := 0; i < len(publicWitnesses); i++ {
+		w, ok := publicWitnesses[i].Vector.(*witness_bls24315.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = *w
+	}
+	return res, nil
+}
+
+func publicWitnessesbw6633(publicWitnesses []*witness.Witness) ([]witness_bw6633.Witness, error) {
+	res := make([]witness_bw6633.Witness, len(publicWitnesses))
+	for i := 0; i < len(publicWitnesses); i++ {
+		w, ok := publicWitnesses[i].Vector.(*witness_bw6633.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		res[i] = *w
+	}
+	return res, nil
+}
@@ -0,0 +1,140 @@
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	assert := require.New(t)
+
+	curves := []ecc.ID{ecc.BN254, ecc.BLS12_381}
+	if !testing.Short() {
+		curves = ecc.Implemented()
+	}
+
+	for _, curve := range curves {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		const n = 8
+		proofs := make([]Proof, n)
+		publicWitnesses := make([]*witness.Witness, n)
+		for i := 0; i < n; i++ {
+			x := i + 1
+			w, err := frontend.NewWitness(&mpcCircuit{X: x, Y: x*x*x + 3*x + 5}, curve)
+			assert.NoError(err)
+			proofs[i], err = Prove(ccs, pk, w)
+			assert.NoError(err)
+			publicWitnesses[i], err = w.Public()
+			assert.NoError(err)
+		}
+
+		srs, err := SetupAggregation(curve, 16)
+		assert.NoError(err)
+		srs = cloneAggregationSRS(t, srs)
+
+		aggregate, err := Aggregate(srs, vk, proofs, publicWitnesses)
+		assert.NoError(err)
+		aggregate = cloneAggregateProof(t, aggregate)
+		assert.NoError(VerifyAggregate(srs, vk, aggregate, publicWitnesses))
+
+		// the public witnesses must match the proofs, in order
+		swapped := append([]*witness.Witness(nil), publicWitnesses...)
+		swapped[0], swapped[1] = swapped[1], swapped[0]
+		assert.Error(VerifyAggregate(srs, vk, aggregate, swapped))
+
+		// a proof for another public input doesn't aggregate to a valid proof
+		wrong := append([]Proof(nil), proofs...)
+		wrong[0] = proofs[1]
+		aggregate, err = Aggregate(srs, vk, wrong, publicWitnesses)
+		assert.NoError(err)
+		assert.Error(VerifyAggregate(srs, vk, aggregate, publicWitnesses))
+
+		// a subset of the proofs, with an SRS supporting more
+		aggregate, err = Aggregate(srs, vk, proofs[:2], publicWitnesses[:2])
+		assert.NoError(err)
+		assert.NoError(VerifyAggregate(srs, vk, aggregate, publicWitnesses[:2]))
+		assert.Error(VerifyAggregate(srs, vk, aggregate, publicWitnesses[:4]))
+
+		// the proofs are padded to a power of two, the padding doesn't verify for the
+		// padded public witnesses
+		for _, nbProofs := range []int{1, 3} {
+			aggregate, err = Aggregate(srs, vk, proofs[:nbProofs], publicWitnesses[:nbProofs])
+			assert.NoError(err)
+			aggregate = cloneAggregateProof(t, aggregate)
+			assert.NoError(VerifyAggregate(srs, vk, aggregate, publicWitnesses[:nbProofs]))
+		}
+		padded := append(append([]*witness.Witness(nil), publicWitnesses[:3]...), publicWitnesses[2])
+		assert.Error(VerifyAggregate(srs, vk, aggregate, padded))
+
+		_, err = Aggregate(srs, vk, nil, nil)
+		assert.Error(err)
+	}
+}
+
+func TestAggregationSRSFromPhase1(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs)
+	assert.NoError(err)
+
+	p1, err := InitPhase1(ecc.BN254, 2)
+	assert.NoError(err)
+	assert.NoError(p1.Contribute())
+	p2, err := InitPhase1(ecc.BN254, 2)
+	assert.NoError(err)
+	assert.NoError(p2.Contribute())
+
+	srs, err := SetupAggregationFromPhase1(p1, p2, 4)
+	assert.NoError(err)
+	_, err = SetupAggregationFromPhase1(p1, p1, 4)
+	assert.Error(err, "the ceremonies must be independent")
+	_, err = SetupAggregationFromPhase1(p1, p2, 8)
+	assert.Error(err, "the ceremonies are too small")
+
+	proofs := make([]Proof, 4)
+	publicWitnesses := make([]*witness.Witness, 4)
+	for i := 0; i < 4; i++ {
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, ecc.BN254)
+		assert.NoError(err)
+		proofs[i], err = Prove(ccs, pk, w)
+		assert.NoError(err)
+		publicWitnesses[i], err = w.Public()
+		assert.NoError(err)
+	}
+	aggregate, err := Aggregate(srs, vk, proofs, publicWitnesses)
+	assert.NoError(err)
+	assert.NoError(VerifyAggregate(srs, vk, aggregate, publicWitnesses))
+}
+
+func cloneAggregationSRS(t *testing.T, srs AggregationSRS) AggregationSRS {
+	var buf bytes.Buffer
+	_, err := srs.WriteTo(&buf)
+	require.NoError(t, err)
+	res := NewAggregationSRS(srs.CurveID())
+	_, err = res.ReadFrom(&buf)
+	require.NoError(t, err)
+	return res
+}
+
+func cloneAggregateProof(t *testing.T, proof AggregateProof) AggregateProof {
+	var buf bytes.Buffer
+	_, err := proof.WriteTo(&buf)
+	require.NoError(t, err)
+	res := NewAggregateProof(proof.CurveID())
+	_, err = res.ReadFrom(&buf)
+	require.NoError(t, err)
+	return res
+}
@@ -0,0 +1,72 @@
+package groth16
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchProve(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs)
+	assert.NoError(err)
+
+	const n = 5
+	witnesses := make([]*witness.Witness, n)
+	for i := 0; i < n; i++ {
+		x := i + 1
+		y := x*x*x + 3*x + 5
+		if i == 1 || i == 3 {
+			y++ // not a solution
+		}
+		witnesses[i], err = frontend.NewWitness(&mpcCircuit{X: x, Y: y}, ecc.BN254)
+		assert.NoError(err)
+	}
+
+	for _, precompute := range []bool{false, true} {
+		if precompute {
+			// the multi-exponentiations of the batch then use the fixed-base tables
+			assert.NoError(pk.Precompute(4))
+		}
+		for _, nbTasks := range []int{1, 4} {
+			proofs, err := BatchProve(ccs, pk, witnesses, backend.WithNbTasks(nbTasks))
+			var batchErr *backend.BatchError
+			assert.True(errors.As(err, &batchErr))
+			assert.Len(batchErr.Errs, n)
+			assert.Len(proofs, n)
+			for i := 0; i < n; i++ {
+				if i == 1 || i == 3 {
+					assert.Error(batchErr.Errs[i])
+					assert.Nil(proofs[i])
+					continue
+				}
+				assert.NoError(batchErr.Errs[i])
+				publicWitness, err := witnesses[i].Public()
+				assert.NoError(err)
+				assert.NoError(Verify(proofs[i], vk, publicWitness))
+			}
+		}
+	}
+
+	proofs, err := BatchProve(ccs, pk, []*witness.Witness{witnesses[0], witnesses[2]})
+	assert.NoError(err)
+	assert.Len(proofs, 2)
+
+	// a nil witness fails alone
+	proofs, err = BatchProve(ccs, pk, []*witness.Witness{witnesses[0], nil})
+	var batchErr *backend.BatchError
+	assert.True(errors.As(err, &batchErr))
+	assert.NoError(batchErr.Errs[0])
+	assert.NotNil(proofs[0])
+	assert.ErrorIs(batchErr.Errs[1], witness.ErrInvalidWitness)
+	assert.Nil(proofs[1])
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/backend/witness"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+)
+
+// The functions below decode Groth16 objects produced by bellman
+// (https://github.com/zkcrypto/bellman), as used by Zcash and Filecoin, on BLS12-381.
+// The resulting objects can be used with Verify.
+//
+// bellman's Parameters (proving key) are not supported: bellman drops the points at
+// infinity of the proving key queries and orders the wires differently than gnark, so
+// they can't be mapped to a ProvingKey without the constraint system they were built from.
+
+// ReadBellmanVerifyingKey decodes a BLS12-381 verifying key serialized with bellman's
+// VerifyingKey::write:
+//
+//	[α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(IC)),[IC]1
+//
+// points are uncompressed, and checked to be on the curve and in the prime order subgroups.
+func ReadBellmanVerifyingKey(r io.Reader) (VerifyingKey, error) {
+	vk := &groth16_bls12381.VerifyingKey{}
+	if _, err := vk.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if len(vk.G1.K) == 0 {
+		return nil, errors.New("invalid bellman verifying key: empty IC")
+	}
+	return vk, nil
+}
+
+// ReadBellmanProof decodes a BLS12-381 proof serialized with bellman's Proof::write:
+//
+//	[A]1,[B]2,[C]1
+//
+// points are compressed, and checked to be on the curve and in the prime order subgroups.
+func ReadBellmanProof(r io.Reader) (Proof, error) {
+	proof := &groth16_bls12381.Proof{}
+	if _, err := proof.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// NewBellmanPublicWitness returns the public witness to use with a bellman verifying key
+// from the concatenation of the public inputs, each one encoded as a 32 bytes big-endian
+// BLS12-381 scalar. The inputs are given in bellman's order (excluding the constant one).
+func NewBellmanPublicWitness(inputs []byte) (*witness.Witness, error) {
+	if len(inputs)%fr.Bytes != 0 {
+		return nil, witness.ErrInvalidWitness
+	}
+
+	// binary encoding of the witness: [uint32(len) | elements (big endian)]
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, uint32(len(inputs)/fr.Bytes)); err != nil {
+		return nil, err
+	}
+	buf.Write(inputs)
+
+	w := &witness.Witness{
+		CurveID: ecc.BLS12_381,
+	}
+	if err := w.UnmarshalBinary(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
@@ -122,3 +122,28 @@ func TestVerifyBellmanProof(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifyBellmanEncoding(t *testing.T) {
+	// first test vector of TestVerifyBellmanProof
+	vkBytes, err := base64.StdEncoding.DecodeString("hwk883gUlTKCyXYA6XWZa8H9/xKIYZaJ0xEs0M5hQOMxiGpxocuX/8maSDmeCk3bhwk883gUlTKCyXYA6XWZa8H9/xKIYZaJ0xEs0M5hQOMxiGpxocuX/8maSDmeCk3bo5ViaDBdO7ZBxAhLSe5k/5TFQyF5Lv7KN2tLKnwgoWMqB16OL8WdbePIwTCuPtJNAFKoTZylLDbSf02kckMcZQDPF9iGh+JC99Pio74vDpwTEjUx5tQ99gNQwxULtztsqDRsPnEvKvLmsxHt8LQVBkEBm2PBJFY+OXf1MNW021viDBpR10mX4WQ6zrsGL5L0GY4cwf4tlbh+Obit+LnN/SQTnREf8fPpdKZ1sa/ui3pGi8lMT6io4D7Ujlwx2RdChwk883gUlTKCyXYA6XWZa8H9/xKIYZaJ0xEs0M5hQOMxiGpxocuX/8maSDmeCk3bkBF+isfMf77HCEGsZANw0hSrO2FGg14Sl26xLAIohdaW8O7gEaag8JdVAZ3OVLd5Df1NkZBEr753Xb8WwaXsJjE7qxwINL1KdqA4+EiYW4edb7+a9bbBeOPtb67ZxmFqAAAAAoMkzUv+KG8WoXszZI5NNMrbMLBDYP/xHunVgSWcix/kBrGlNozv1uFr0cmYZiij3YqToYs+EZa3dl2ILHx7H1n+b+Bjky/td2QduHVtf5t/Z9sKCfr+vOn12zVvOVz/6w==")
+	require.NoError(t, err)
+	proofBytes, err := base64.StdEncoding.DecodeString("lvQLU/KqgFhsLkt/5C/scqs7nWR+eYtyPdWiLVBux9GblT4AhHYMdCgwQfSJcudvsgV6fXoK+DUSRgJ++Nqt+Wvb7GlYlHpxCysQhz26TTu8Nyo7zpmVPH92+UYmbvbQCSvX2BhWtvkfHmqDVjmSIQ4RUMfeveA1KZbSf999NE4qKK8Do+8oXcmTM4LZVmh1rlyqznIdFXPN7x3pD4E0gb6/y69xtWMChv9654FMg05bAdueKt9uA4BEcAbpkdHF")
+	require.NoError(t, err)
+	inputsBytes, err := base64.StdEncoding.DecodeString("LcMT3OOlkHLzJBKCKjjzzVMg+r+FVgd52LlhZPB4RFg=")
+	require.NoError(t, err)
+
+	vk, err := ReadBellmanVerifyingKey(bytes.NewReader(vkBytes))
+	require.NoError(t, err)
+	proof, err := ReadBellmanProof(bytes.NewReader(proofBytes))
+	require.NoError(t, err)
+	witness, err := NewBellmanPublicWitness(inputsBytes)
+	require.NoError(t, err)
+
+	require.NoError(t, Verify(proof, vk, witness))
+
+	// wrong public input
+	inputsBytes[len(inputsBytes)-1] ^= 1
+	witness, err = NewBellmanPublicWitness(inputsBytes)
+	require.NoError(t, err)
+	require.Error(t, Verify(proof, vk, witness))
+}
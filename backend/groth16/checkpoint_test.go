@@ -0,0 +1,65 @@
+package groth16
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+// cancelOnCheckpoint cancels a proof once it saved a checkpoint
+type cancelOnCheckpoint struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelOnCheckpoint) ObservePhase(m backend.PhaseMetrics) {
+	if m.Name == "checkpoint" {
+		c.cancel()
+	}
+}
+
+func TestResume(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range ecc.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+
+		path := filepath.Join(t.TempDir(), "proof.checkpoint")
+
+		// interrupt the proof before the multi-exponentiations
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err = Prove(ccs, pk, w, backend.WithCheckpoint(path), backend.WithContext(ctx), backend.WithObserver(cancelOnCheckpoint{cancel}))
+		assert.ErrorIs(err, context.Canceled, curve.String())
+
+		f, err := os.Open(path)
+		assert.NoError(err)
+		proof, err := Resume(ccs, pk, w, f)
+		assert.NoError(err, curve.String())
+		assert.NoError(Verify(proof, vk, publicWitness), curve.String())
+
+		// a checkpoint is bound to its witness
+		other, err := frontend.NewWitness(&mpcCircuit{X: 2, Y: 19}, curve)
+		assert.NoError(err)
+		_, err = f.Seek(0, 0)
+		assert.NoError(err)
+		_, err = Resume(ccs, pk, other, f)
+		assert.ErrorIs(err, backend.ErrCheckpointMismatch, curve.String())
+		f.Close()
+
+		_, err = NewProver(ccs, pk, backend.WithCheckpoint(path))
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,78 @@
+package groth16
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, ecc.BN254)
+	assert.NoError(err)
+	proof, err := Prove(ccs, pk, w)
+	assert.NoError(err)
+
+	var ccsBuf, pkBuf, vkBuf, proofBuf, witnessBuf bytes.Buffer
+	_, err = WriteCS(&ccsBuf, ccs)
+	assert.NoError(err)
+	_, err = WriteProvingKey(&pkBuf, pk)
+	assert.NoError(err)
+	_, err = WriteVerifyingKey(&vkBuf, vk)
+	assert.NoError(err)
+	_, err = WriteProof(&proofBuf, proof)
+	assert.NoError(err)
+	_, err = w.WriteTo(&witnessBuf)
+	assert.NoError(err)
+	vkBytes := append([]byte(nil), vkBuf.Bytes()...)
+
+	// the readers instantiate the objects from the header
+	ccs, _, err = ReadCS(&ccsBuf, ecc.UNKNOWN)
+	assert.NoError(err)
+	pk, _, err = ReadProvingKey(&pkBuf, ecc.BN254)
+	assert.NoError(err)
+	vk, _, err = ReadVerifyingKey(&vkBuf, ecc.BN254)
+	assert.NoError(err)
+	_, err = w.ReadFrom(&witnessBuf)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	proof, _, err = ReadProof(&proofBuf, ecc.UNKNOWN)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+	proof, err = Prove(ccs, pk, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	var mismatch *gnarkio.MismatchError
+	_, _, err = ReadVerifyingKey(bytes.NewReader(vkBytes), ecc.BLS12_381)
+	assert.True(errors.As(err, &mismatch), "wrong curve")
+	assert.Equal("curve", mismatch.Field)
+	_, _, err = ReadProvingKey(bytes.NewReader(vkBytes), ecc.UNKNOWN)
+	assert.True(errors.As(err, &mismatch), "wrong kind")
+	assert.Equal("kind", mismatch.Field)
+
+	// the raw encoding of the verifying key is also a container
+	var raw bytes.Buffer
+	_, err = vk.WriteRawTo(&raw)
+	assert.NoError(err)
+	vk, _, err = ReadVerifyingKey(&raw, ecc.UNKNOWN)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	// a bare encoding can only be read on a given curve
+	_, _, err = ReadVerifyingKey(bytes.NewReader([]byte("not a container")), ecc.UNKNOWN)
+	assert.ErrorIs(err, gnarkio.ErrInvalidMagic)
+}
@@ -0,0 +1,70 @@
+package groth16
+
+import (
+	"context"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range ecc.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err = SetupContext(ctx, ccs)
+		assert.ErrorIs(err, context.Canceled)
+
+		pk, vk, err := SetupContext(context.Background(), ccs)
+		assert.NoError(err)
+		_, err = Prove(ccs, pk, w, backend.WithContext(ctx))
+		assert.ErrorIs(err, context.Canceled)
+
+		proof, err := Prove(ccs, pk, w, backend.WithContext(context.Background()))
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+	}
+}
+
+// cancelOnPhase cancels a context when a phase of Prove ends
+type cancelOnPhase struct {
+	phase  string
+	cancel context.CancelFunc
+}
+
+func (c cancelOnPhase) ObservePhase(m backend.PhaseMetrics) {
+	if m.Name == c.phase {
+		c.cancel()
+	}
+}
+
+func TestContextCancelDuringProve(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	pk, _, err := Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, ecc.BN254)
+	assert.NoError(err)
+
+	// "msm K" ends after the context was last checked before the multi-exponentiations
+	for _, phase := range []string{"solve", "fft h", "msm K"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		_, err = Prove(ccs, pk, w, backend.WithContext(ctx), backend.WithObserver(cancelOnPhase{phase, cancel}))
+		assert.ErrorIs(err, context.Canceled, phase)
+		cancel()
+	}
+}
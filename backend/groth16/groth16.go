@@ -20,6 +20,8 @@
 package groth16
 
 import (
+	"context"
+	"errors"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -68,8 +70,17 @@ type groth16Object interface {
 // Proof represents a Groth16 proof generated by groth16.Prove
 //
 // it's underlying implementation is curve specific (see gnark/internal/backend)
+//
+// MarshalSnarkJS and UnmarshalSnarkJS are implemented for BN254 and BLS12-381 and
+// will return an error with other curves
 type Proof interface {
 	groth16Object
+
+	// MarshalSnarkJS encodes the proof as snarkjs's proof.json
+	MarshalSnarkJS() ([]byte, error)
+
+	// UnmarshalSnarkJS decodes a proof encoded as snarkjs's proof.json
+	UnmarshalSnarkJS(data []byte) error
 }
 
 // ProvingKey represents a Groth16 ProvingKey
@@ -86,6 +97,25 @@ type ProvingKey interface {
 	NbG2() int
 
 	IsDifferent(interface{}) bool
+
+	// Precompute computes fixed-base tables of the points of the ProvingKey, with windows
+	// of windowSize bits, which Prove then uses to speed up its multi-exponentiations
+	Precompute(windowSize int) error
+
+	// IsPrecomputed returns true if the ProvingKey holds fixed-base tables
+	IsPrecomputed() bool
+
+	// WritePrecomputedTo writes the fixed-base tables, which are not part of the ProvingKey
+	// encoding, to w
+	WritePrecomputedTo(w io.Writer) (int64, error)
+
+	// ReadPrecomputedFrom reads fixed-base tables written by WritePrecomputedTo, and sets them
+	// in the ProvingKey if they match it
+	ReadPrecomputedFrom(r io.Reader) (int64, error)
+
+	// UnsafeReadPrecomputedFrom behaves like ReadPrecomputedFrom excepts it doesn't check if the
+	// decoded points are on the curve or in the correct subgroup
+	UnsafeReadPrecomputedFrom(r io.Reader) (int64, error)
 }
 
 // VerifyingKey represents a Groth16 VerifyingKey
@@ -93,6 +123,9 @@ type ProvingKey interface {
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
 //
 // ExportSolidity is implemented for BN254 and will return an error with other curves
+//
+// MarshalSnarkJS and UnmarshalSnarkJS are implemented for BN254 and BLS12-381 and
+// will return an error with other curves
 type VerifyingKey interface {
 	groth16Object
 	gnarkio.UnsafeReaderFrom
@@ -110,6 +143,12 @@ type VerifyingKey interface {
 	// this will return an error if not supported on the CurveID()
 	ExportSolidity(w io.Writer) error
 
+	// MarshalSnarkJS encodes the VerifyingKey as snarkjs's verification_key.json
+	MarshalSnarkJS() ([]byte, error)
+
+	// UnmarshalSnarkJS decodes a VerifyingKey encoded as snarkjs's verification_key.json
+	UnmarshalSnarkJS(data []byte) error
+
 	IsDifferent(interface{}) bool
 }
 
@@ -158,6 +197,56 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness *witness.Witness) error
 	}
 }
 
+// Rerandomize returns a new proof of the statement proven by proof, which can't be linked
+// to it: for random r₁, r₂, A' = r₁⁻¹·A, B' = r₁·B + r₁r₂·[δ]2 and C' = C + r₂·A.
+//
+// The rerandomized proof verifies with vk if and only if proof does.
+func Rerandomize(proof Proof, vk VerifyingKey) (Proof, error) {
+	if proof.CurveID() != vk.CurveID() {
+		return nil, errors.New("proof and verifying key are defined on different curves")
+	}
+	switch _proof := proof.(type) {
+	case *groth16_bls12377.Proof:
+		res, err := groth16_bls12377.Rerandomize(_proof, vk.(*groth16_bls12377.VerifyingKey))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case *groth16_bls12381.Proof:
+		res, err := groth16_bls12381.Rerandomize(_proof, vk.(*groth16_bls12381.VerifyingKey))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case *groth16_bn254.Proof:
+		res, err := groth16_bn254.Rerandomize(_proof, vk.(*groth16_bn254.VerifyingKey))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case *groth16_bw6761.Proof:
+		res, err := groth16_bw6761.Rerandomize(_proof, vk.(*groth16_bw6761.VerifyingKey))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case *groth16_bls24315.Proof:
+		res, err := groth16_bls24315.Rerandomize(_proof, vk.(*groth16_bls24315.VerifyingKey))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	case *groth16_bw6633.Proof:
+		res, err := groth16_bw6633.Rerandomize(_proof, vk.(*groth16_bw6633.VerifyingKey))
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	default:
+		panic("unrecognized proof curve type")
+	}
+}
+
 // Prove runs the groth16.Prove algorithm.
 //
 // if the force flag is set:
@@ -222,48 +311,60 @@ func Prove(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness *w
 //
 // Two main solutions to this deployment issues are: running the Setup through a MPC (multi party computation)
 // or using a ZKP backend like PLONK where the per-circuit Setup is deterministic.
-func Setup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, error) {
+//
+// See backend.WithSetupNbTasks to bound the number of concurrent tasks of the setup.
+func Setup(r1cs frontend.CompiledConstraintSystem, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
+	return SetupContext(context.Background(), r1cs, opts...)
+}
+
+// SetupContext behaves like Setup, and returns ctx.Err() if ctx is done before the
+// setup completes
+func SetupContext(ctx context.Context, r1cs frontend.CompiledConstraintSystem, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
+	opt, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	switch _r1cs := r1cs.(type) {
 	case *backend_bls12377.R1CS:
 		var pk groth16_bls12377.ProvingKey
 		var vk groth16_bls12377.VerifyingKey
-		if err := groth16_bls12377.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls12377.SetupContext(ctx, _r1cs, &pk, &vk, opt); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *backend_bls12381.R1CS:
 		var pk groth16_bls12381.ProvingKey
 		var vk groth16_bls12381.VerifyingKey
-		if err := groth16_bls12381.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls12381.SetupContext(ctx, _r1cs, &pk, &vk, opt); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *backend_bn254.R1CS:
 		var pk groth16_bn254.ProvingKey
 		var vk groth16_bn254.VerifyingKey
-		if err := groth16_bn254.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bn254.SetupContext(ctx, _r1cs, &pk, &vk, opt); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *backend_bw6761.R1CS:
 		var pk groth16_bw6761.ProvingKey
 		var vk groth16_bw6761.VerifyingKey
-		if err := groth16_bw6761.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bw6761.SetupContext(ctx, _r1cs, &pk, &vk, opt); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *backend_bls24315.R1CS:
 		var pk groth16_bls24315.ProvingKey
 		var vk groth16_bls24315.VerifyingKey
-		if err := groth16_bls24315.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bls24315.SetupContext(ctx, _r1cs, &pk, &vk, opt); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
 	case *backend_bw6633.R1CS:
 		var pk groth16_bw6633.ProvingKey
 		var vk groth16_bw6633.VerifyingKey
-		if err := groth16_bw6633.Setup(_r1cs, &pk, &vk); err != nil {
+		if err := groth16_bw6633.SetupContext(ctx, _r1cs, &pk, &vk, opt); err != nil {
 			return nil, nil, err
 		}
 		return &pk, &vk, nil
@@ -272,6 +373,42 @@ func Setup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, VerifyingKey, er
 	}
 }
 
+// SetupTo behaves like Setup, but writes the ProvingKey to pkWriter and the VerifyingKey to
+// vkWriter instead of returning them. The keys are encoded as with WriteRawTo, and can be
+// read back with ReadFrom.
+//
+// The proving key is computed and written incrementally, so its points are never all held in
+// memory; this bounds the memory needed to setup very large circuits.
+func SetupTo(r1cs frontend.CompiledConstraintSystem, pkWriter, vkWriter io.Writer, opts ...backend.SetupOption) error {
+	return SetupToContext(context.Background(), r1cs, pkWriter, vkWriter, opts...)
+}
+
+// SetupToContext behaves like SetupTo, and returns ctx.Err() if ctx is done before the
+// setup completes
+func SetupToContext(ctx context.Context, r1cs frontend.CompiledConstraintSystem, pkWriter, vkWriter io.Writer, opts ...backend.SetupOption) error {
+	opt, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		return groth16_bls12377.SetupTo(ctx, _r1cs, pkWriter, vkWriter, opt)
+	case *backend_bls12381.R1CS:
+		return groth16_bls12381.SetupTo(ctx, _r1cs, pkWriter, vkWriter, opt)
+	case *backend_bn254.R1CS:
+		return groth16_bn254.SetupTo(ctx, _r1cs, pkWriter, vkWriter, opt)
+	case *backend_bw6761.R1CS:
+		return groth16_bw6761.SetupTo(ctx, _r1cs, pkWriter, vkWriter, opt)
+	case *backend_bls24315.R1CS:
+		return groth16_bls24315.SetupTo(ctx, _r1cs, pkWriter, vkWriter, opt)
+	case *backend_bw6633.R1CS:
+		return groth16_bw6633.SetupTo(ctx, _r1cs, pkWriter, vkWriter, opt)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
 // DummySetup create a random ProvingKey with provided R1CS
 // it doesn't return a VerifyingKey and is use for benchmarking or test purposes only.
 func DummySetup(r1cs frontend.CompiledConstraintSystem) (ProvingKey, error) {
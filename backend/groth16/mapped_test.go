@@ -0,0 +1,50 @@
+package groth16
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapProvingKey(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		path := filepath.Join(t.TempDir(), "pk")
+		f, err := os.Create(path)
+		assert.NoError(err)
+		_, err = WriteMappedProvingKey(f, pk)
+		assert.NoError(err)
+		assert.NoError(f.Close())
+
+		mapped, closer, err := MapProvingKey(path, curve)
+		assert.NoError(err)
+		assert.False(pk.IsDifferent(mapped))
+
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		proof, err := Prove(ccs, mapped, w)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+		assert.NoError(closer.Close())
+
+		_, _, err = MapProvingKey(path, ecc.BW6_761)
+		var mismatch *gnarkio.MismatchError
+		assert.True(errors.As(err, &mismatch))
+	}
+}
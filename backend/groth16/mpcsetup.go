@@ -0,0 +1,541 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+)
+
+// The MPC (multi party computation) setup replaces the toxic waste sampled by Setup
+// with the product of secrets contributed by several participants: the keys are
+// secure as long as one of them discarded their secret.
+//
+// It follows https://eprint.iacr.org/2017/1050.pdf and runs in two phases:
+//
+//	1. a circuit independent powers of tau ceremony: InitPhase1, then Phase1.Contribute
+//	   for each participant. It can be reused for all the circuits of up to 2ᵖᵒʷᵉʳ
+//	   constraints.
+//	2. a circuit specific ceremony: InitPhase2 from the constraint system and the final
+//	   state of the phase 1, then Phase2.Contribute for each participant.
+//
+// Each contribution is serialized (WriteTo) and passed to the next participant, and
+// anyone can check the transcripts with VerifyPhase1 and VerifyPhase2. ExtractKeys
+// returns the ProvingKey and VerifyingKey from the final states of both phases.
+
+// ErrCurveMismatch is returned when the objects of a MPC setup are defined on different curves
+var ErrCurveMismatch = errors.New("MPC setup objects are defined on different curves")
+
+// Phase1 is the state of a powers of tau ceremony, after a contribution
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type Phase1 interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+
+	// Contribute updates the parameters with secrets sampled and discarded
+	// by the participant, along with a proof of knowledge of these secrets
+	Contribute() error
+}
+
+// Phase2 is the state of the circuit specific phase of the MPC setup, after a contribution
+//
+// it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
+type Phase2 interface {
+	io.WriterTo
+	io.ReaderFrom
+	CurveID() ecc.ID
+
+	// Contribute updates δ with a secret sampled and discarded by the
+	// participant, along with a proof of knowledge of this secret
+	Contribute() error
+}
+
+// InitPhase1 returns the initial state of a powers of tau ceremony on the curve,
+// for circuits of up to 2ᵖᵒʷᵉʳ constraints
+func InitPhase1(curveID ecc.ID, power int) (Phase1, error) {
+	switch curveID {
+	case ecc.BN254:
+		phase, err := groth16_bn254.InitPhase1(power)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case ecc.BLS12_377:
+		phase, err := groth16_bls12377.InitPhase1(power)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case ecc.BLS12_381:
+		phase, err := groth16_bls12381.InitPhase1(power)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case ecc.BW6_761:
+		phase, err := groth16_bw6761.InitPhase1(power)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case ecc.BLS24_315:
+		phase, err := groth16_bls24315.InitPhase1(power)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case ecc.BW6_633:
+		phase, err := groth16_bw6633.InitPhase1(power)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	default:
+		panic("not implemented")
+	}
+}
+
+// NewPhase1 instantiates a curve-typed Phase1 and returns an interface
+// This function exists for serialization purposes
+func NewPhase1(curveID ecc.ID) Phase1 {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.Phase1{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.Phase1{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.Phase1{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.Phase1{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.Phase1{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.Phase1{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// VerifyPhase1 checks a powers of tau transcript: c0 must be the initial state
+// returned by InitPhase1, and each contribution a valid update of the previous one
+func VerifyPhase1(c0, c1 Phase1, c ...Phase1) error {
+	contributions := append([]Phase1{c0, c1}, c...)
+	switch c0.(type) {
+	case *groth16_bn254.Phase1:
+		_c := make([]*groth16_bn254.Phase1, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			var ok bool
+			if _c[i], ok = contributions[i].(*groth16_bn254.Phase1); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		return groth16_bn254.VerifyPhase1(_c[0], _c[1], _c[2:]...)
+	case *groth16_bls12377.Phase1:
+		_c := make([]*groth16_bls12377.Phase1, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			var ok bool
+			if _c[i], ok = contributions[i].(*groth16_bls12377.Phase1); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		return groth16_bls12377.VerifyPhase1(_c[0], _c[1], _c[2:]...)
+	case *groth16_bls12381.Phase1:
+		_c := make([]*groth16_bls12381.Phase1, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			var ok bool
+			if _c[i], ok = contributions[i].(*groth16_bls12381.Phase1); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		return groth16_bls12381.VerifyPhase1(_c[0], _c[1], _c[2:]...)
+	case *groth16_bw6761.Phase1:
+		_c := make([]*groth16_bw6761.Phase1, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			var ok bool
+			if _c[i], ok = contributions[i].(*groth16_bw6761.Phase1); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		return groth16_bw6761.VerifyPhase1(_c[0], _c[1], _c[2:]...)
+	case *groth16_bls24315.Phase1:
+		_c := make([]*groth16_bls24315.Phase1, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			var ok bool
+			if _c[i], ok = contributions[i].(*groth16_bls24315.Phase1); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		return groth16_bls24315.VerifyPhase1(_c[0], _c[1], _c[2:]...)
+	case *groth16_bw6633.Phase1:
+		_c := make([]*groth16_bw6633.Phase1, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			var ok bool
+			if _c[i], ok = contributions[i].(*groth16_bw6633.Phase1); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		return groth16_bw6633.VerifyPhase1(_c[0], _c[1], _c[2:]...)
+	default:
+		panic("unrecognized Phase1 curve type")
+	}
+}
+
+// InitPhase2 returns the initial state of the phase 2 for the constraint system, from
+// the final state of a phase 1 supporting at least its number of constraints
+func InitPhase2(r1cs frontend.CompiledConstraintSystem, phase1 Phase1) (Phase2, error) {
+	switch _r1cs := r1cs.(type) {
+	case *backend_bn254.R1CS:
+		_phase1, ok := phase1.(*groth16_bn254.Phase1)
+		if !ok {
+			return nil, ErrCurveMismatch
+		}
+		phase, _, err := groth16_bn254.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case *backend_bls12377.R1CS:
+		_phase1, ok := phase1.(*groth16_bls12377.Phase1)
+		if !ok {
+			return nil, ErrCurveMismatch
+		}
+		phase, _, err := groth16_bls12377.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case *backend_bls12381.R1CS:
+		_phase1, ok := phase1.(*groth16_bls12381.Phase1)
+		if !ok {
+			return nil, ErrCurveMismatch
+		}
+		phase, _, err := groth16_bls12381.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case *backend_bw6761.R1CS:
+		_phase1, ok := phase1.(*groth16_bw6761.Phase1)
+		if !ok {
+			return nil, ErrCurveMismatch
+		}
+		phase, _, err := groth16_bw6761.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case *backend_bls24315.R1CS:
+		_phase1, ok := phase1.(*groth16_bls24315.Phase1)
+		if !ok {
+			return nil, ErrCurveMismatch
+		}
+		phase, _, err := groth16_bls24315.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	case *backend_bw6633.R1CS:
+		_phase1, ok := phase1.(*groth16_bw6633.Phase1)
+		if !ok {
+			return nil, ErrCurveMismatch
+		}
+		phase, _, err := groth16_bw6633.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, err
+		}
+		return &phase, nil
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// NewPhase2 instantiates a curve-typed Phase2 and returns an interface
+// This function exists for serialization purposes
+func NewPhase2(curveID ecc.ID) Phase2 {
+	switch curveID {
+	case ecc.BN254:
+		return &groth16_bn254.Phase2{}
+	case ecc.BLS12_377:
+		return &groth16_bls12377.Phase2{}
+	case ecc.BLS12_381:
+		return &groth16_bls12381.Phase2{}
+	case ecc.BW6_761:
+		return &groth16_bw6761.Phase2{}
+	case ecc.BLS24_315:
+		return &groth16_bls24315.Phase2{}
+	case ecc.BW6_633:
+		return &groth16_bw6633.Phase2{}
+	default:
+		panic("not implemented")
+	}
+}
+
+// VerifyPhase2 checks the transcript of the phase 2 of the constraint system: c0 must
+// be the initial state computed by InitPhase2 from r1cs and phase1, and each contribution
+// a valid update of the previous one. phase1 is checked separately with VerifyPhase1.
+func VerifyPhase2(r1cs frontend.CompiledConstraintSystem, phase1 Phase1, c0, c1 Phase2, c ...Phase2) error {
+	contributions := append([]Phase2{c0, c1}, c...)
+	switch _r1cs := r1cs.(type) {
+	case *backend_bn254.R1CS:
+		_phase1, ok := phase1.(*groth16_bn254.Phase1)
+		if !ok {
+			return ErrCurveMismatch
+		}
+		_c := make([]*groth16_bn254.Phase2, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			if _c[i], ok = contributions[i].(*groth16_bn254.Phase2); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		expected, _, err := groth16_bn254.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return err
+		}
+		if err := checkInitPhase2(&expected, _c[0]); err != nil {
+			return err
+		}
+		return groth16_bn254.VerifyPhase2(_c[0], _c[1], _c[2:]...)
+	case *backend_bls12377.R1CS:
+		_phase1, ok := phase1.(*groth16_bls12377.Phase1)
+		if !ok {
+			return ErrCurveMismatch
+		}
+		_c := make([]*groth16_bls12377.Phase2, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			if _c[i], ok = contributions[i].(*groth16_bls12377.Phase2); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		expected, _, err := groth16_bls12377.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return err
+		}
+		if err := checkInitPhase2(&expected, _c[0]); err != nil {
+			return err
+		}
+		return groth16_bls12377.VerifyPhase2(_c[0], _c[1], _c[2:]...)
+	case *backend_bls12381.R1CS:
+		_phase1, ok := phase1.(*groth16_bls12381.Phase1)
+		if !ok {
+			return ErrCurveMismatch
+		}
+		_c := make([]*groth16_bls12381.Phase2, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			if _c[i], ok = contributions[i].(*groth16_bls12381.Phase2); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		expected, _, err := groth16_bls12381.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return err
+		}
+		if err := checkInitPhase2(&expected, _c[0]); err != nil {
+			return err
+		}
+		return groth16_bls12381.VerifyPhase2(_c[0], _c[1], _c[2:]...)
+	case *backend_bw6761.R1CS:
+		_phase1, ok := phase1.(*groth16_bw6761.Phase1)
+		if !ok {
+			return ErrCurveMismatch
+		}
+		_c := make([]*groth16_bw6761.Phase2, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			if _c[i], ok = contributions[i].(*groth16_bw6761.Phase2); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		expected, _, err := groth16_bw6761.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return err
+		}
+		if err := checkInitPhase2(&expected, _c[0]); err != nil {
+			return err
+		}
+		return groth16_bw6761.VerifyPhase2(_c[0], _c[1], _c[2:]...)
+	case *backend_bls24315.R1CS:
+		_phase1, ok := phase1.(*groth16_bls24315.Phase1)
+		if !ok {
+			return ErrCurveMismatch
+		}
+		_c := make([]*groth16_bls24315.Phase2, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			if _c[i], ok = contributions[i].(*groth16_bls24315.Phase2); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		expected, _, err := groth16_bls24315.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return err
+		}
+		if err := checkInitPhase2(&expected, _c[0]); err != nil {
+			return err
+		}
+		return groth16_bls24315.VerifyPhase2(_c[0], _c[1], _c[2:]...)
+	case *backend_bw6633.R1CS:
+		_phase1, ok := phase1.(*groth16_bw6633.Phase1)
+		if !ok {
+			return ErrCurveMismatch
+		}
+		_c := make([]*groth16_bw6633.Phase2, len(contributions))
+		for i := 0; i < len(_c); i++ {
+			if _c[i], ok = contributions[i].(*groth16_bw6633.Phase2); !ok {
+				return ErrCurveMismatch
+			}
+		}
+		expected, _, err := groth16_bw6633.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return err
+		}
+		if err := checkInitPhase2(&expected, _c[0]); err != nil {
+			return err
+		}
+		return groth16_bw6633.VerifyPhase2(_c[0], _c[1], _c[2:]...)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// ExtractKeys returns the ProvingKey and VerifyingKey of the constraint system from the
+// final states of both phases of the MPC setup
+func ExtractKeys(r1cs frontend.CompiledConstraintSystem, phase1 Phase1, phase2 Phase2) (ProvingKey, VerifyingKey, error) {
+	switch _r1cs := r1cs.(type) {
+	case *backend_bn254.R1CS:
+		_phase1, ok1 := phase1.(*groth16_bn254.Phase1)
+		_phase2, ok2 := phase2.(*groth16_bn254.Phase2)
+		if !ok1 || !ok2 {
+			return nil, nil, ErrCurveMismatch
+		}
+		_, evals, err := groth16_bn254.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := groth16_bn254.ExtractKeys(_phase1, _phase2, &evals, len(_r1cs.Constraints))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bls12377.R1CS:
+		_phase1, ok1 := phase1.(*groth16_bls12377.Phase1)
+		_phase2, ok2 := phase2.(*groth16_bls12377.Phase2)
+		if !ok1 || !ok2 {
+			return nil, nil, ErrCurveMismatch
+		}
+		_, evals, err := groth16_bls12377.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := groth16_bls12377.ExtractKeys(_phase1, _phase2, &evals, len(_r1cs.Constraints))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bls12381.R1CS:
+		_phase1, ok1 := phase1.(*groth16_bls12381.Phase1)
+		_phase2, ok2 := phase2.(*groth16_bls12381.Phase2)
+		if !ok1 || !ok2 {
+			return nil, nil, ErrCurveMismatch
+		}
+		_, evals, err := groth16_bls12381.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := groth16_bls12381.ExtractKeys(_phase1, _phase2, &evals, len(_r1cs.Constraints))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bw6761.R1CS:
+		_phase1, ok1 := phase1.(*groth16_bw6761.Phase1)
+		_phase2, ok2 := phase2.(*groth16_bw6761.Phase2)
+		if !ok1 || !ok2 {
+			return nil, nil, ErrCurveMismatch
+		}
+		_, evals, err := groth16_bw6761.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := groth16_bw6761.ExtractKeys(_phase1, _phase2, &evals, len(_r1cs.Constraints))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bls24315.R1CS:
+		_phase1, ok1 := phase1.(*groth16_bls24315.Phase1)
+		_phase2, ok2 := phase2.(*groth16_bls24315.Phase2)
+		if !ok1 || !ok2 {
+			return nil, nil, ErrCurveMismatch
+		}
+		_, evals, err := groth16_bls24315.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := groth16_bls24315.ExtractKeys(_phase1, _phase2, &evals, len(_r1cs.Constraints))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	case *backend_bw6633.R1CS:
+		_phase1, ok1 := phase1.(*groth16_bw6633.Phase1)
+		_phase2, ok2 := phase2.(*groth16_bw6633.Phase2)
+		if !ok1 || !ok2 {
+			return nil, nil, ErrCurveMismatch
+		}
+		_, evals, err := groth16_bw6633.InitPhase2(_r1cs, _phase1)
+		if err != nil {
+			return nil, nil, err
+		}
+		pk, vk, err := groth16_bw6633.ExtractKeys(_phase1, _phase2, &evals, len(_r1cs.Constraints))
+		if err != nil {
+			return nil, nil, err
+		}
+		return &pk, &vk, nil
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// checkInitPhase2 returns an error if the serialized phases differ
+func checkInitPhase2(expected, actual Phase2) error {
+	var b1, b2 bytes.Buffer
+	if _, err := expected.WriteTo(&b1); err != nil {
+		return err
+	}
+	if _, err := actual.WriteTo(&b2); err != nil {
+		return err
+	}
+	if !bytes.Equal(b1.Bytes(), b2.Bytes()) {
+		return errors.New("the initial state of the phase 2 doesn't match the constraint system and the phase 1")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type mpcCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *mpcCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, api.Mul(c.X, 3), 5))
+	return nil
+}
+
+func TestMPCSetup(t *testing.T) {
+	assert := require.New(t)
+
+	curves := []ecc.ID{ecc.BN254}
+	if !testing.Short() {
+		curves = ecc.Implemented()
+	}
+
+	for _, curve := range curves {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+
+		// phase 1, each participant reads the previous contribution
+		p1 := make([]Phase1, 3)
+		p1[0], err = InitPhase1(curve, 3)
+		assert.NoError(err)
+		for i := 1; i < len(p1); i++ {
+			p1[i] = clonePhase1(t, p1[i-1])
+			assert.NoError(p1[i].Contribute())
+		}
+		assert.NoError(VerifyPhase1(p1[0], p1[1], p1[2:]...))
+
+		// phase 2
+		p2 := make([]Phase2, 3)
+		p2[0], err = InitPhase2(ccs, p1[2])
+		assert.NoError(err)
+		for i := 1; i < len(p2); i++ {
+			p2[i] = clonePhase2(t, p2[i-1])
+			assert.NoError(p2[i].Contribute())
+		}
+		assert.NoError(VerifyPhase2(ccs, p1[2], p2[0], p2[1], p2[2:]...))
+
+		// the initial state must match the phase 1
+		assert.Error(VerifyPhase2(ccs, p1[1], p2[0], p2[1], p2[2:]...))
+
+		// prove and verify with the extracted keys
+		pk, vk, err := ExtractKeys(ccs, p1[2], p2[2])
+		assert.NoError(err)
+
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, w)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+
+		wrong, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 42}, curve, frontend.PublicOnly())
+		assert.NoError(err)
+		assert.Error(Verify(proof, vk, wrong))
+	}
+}
+
+func clonePhase1(t *testing.T, phase Phase1) Phase1 {
+	var buf bytes.Buffer
+	_, err := phase.WriteTo(&buf)
+	require.NoError(t, err)
+	res := NewPhase1(phase.CurveID())
+	_, err = res.ReadFrom(&buf)
+	require.NoError(t, err)
+	return res
+}
+
+func clonePhase2(t *testing.T, phase Phase2) Phase2 {
+	var buf bytes.Buffer
+	_, err := phase.WriteTo(&buf)
+	require.NoError(t, err)
+	res := NewPhase2(phase.CurveID())
+	_, err = res.ReadFrom(&buf)
+	require.NoError(t, err)
+	return res
+}
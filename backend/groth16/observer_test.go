@@ -0,0 +1,46 @@
+package groth16
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	pk, _, err := Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, ecc.BN254)
+	assert.NoError(err)
+
+	var report backend.Report
+	_, err = Prove(ccs, pk, w, backend.WithObserver(&report))
+	assert.NoError(err)
+
+	phases := make(map[string]backend.PhaseMetrics)
+	for _, p := range report.Phases() {
+		phases[p.Name] = p
+	}
+	for _, name := range []string{"solve", "filter wires A", "filter wires B", "fft h", "msm A", "msm B1", "msm B2", "msm K", "msm Z"} {
+		_, ok := phases[name]
+		assert.True(ok, "missing phase %s", name)
+	}
+	assert.Equal(ccs.GetNbConstraints(), phases["solve"].NbElements)
+
+	var buf bytes.Buffer
+	assert.NoError(report.WriteJSON(&buf))
+	var decoded struct {
+		Phases []backend.PhaseMetrics `json:"phases"`
+	}
+	assert.NoError(json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(report.Phases(), decoded.Phases)
+}
@@ -0,0 +1,66 @@
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecompute(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		assert.Error(pk.Precompute(1))
+		assert.False(pk.IsPrecomputed())
+		assert.NoError(pk.Precompute(8))
+		assert.True(pk.IsPrecomputed())
+
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, w)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+
+		// the tables are serialized separately from the key
+		var pkBuf, tablesBuf bytes.Buffer
+		_, err = pk.WriteRawTo(&pkBuf)
+		assert.NoError(err)
+		_, err = pk.WritePrecomputedTo(&tablesBuf)
+		assert.NoError(err)
+		tables := tablesBuf.Bytes()
+
+		pkRead := NewProvingKey(curve)
+		_, err = pkRead.ReadFrom(&pkBuf)
+		assert.NoError(err)
+		assert.False(pkRead.IsPrecomputed())
+		_, err = pkRead.ReadPrecomputedFrom(bytes.NewReader(tables))
+		assert.NoError(err)
+		assert.True(pkRead.IsPrecomputed())
+
+		proof, err = Prove(ccs, pkRead, w)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+
+		// tables of another key are rejected
+		other, err := DummySetup(ccs)
+		assert.NoError(err)
+		assert.NoError(other.Precompute(4))
+		tablesBuf.Reset()
+		_, err = other.WritePrecomputedTo(&tablesBuf)
+		assert.NoError(err)
+		_, err = pkRead.UnsafeReadPrecomputedFrom(&tablesBuf)
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package groth16
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	backend_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	backend_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	backend_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	backend_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	backend_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	backend_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+// Prover generates proofs for a fixed constraint system and proving key.
+//
+// Unlike groth16.Prove, it reuses its buffers across calls, which reduces allocations (and GC
+// pressure) when proving many witnesses. It is safe for concurrent use.
+type Prover interface {
+	// Prove generates a proof of knowledge of fullWitness, with the options given to NewProver
+	Prove(fullWitness *witness.Witness) (Proof, error)
+}
+
+type proverFunc func(fullWitness *witness.Witness) (Proof, error)
+
+func (f proverFunc) Prove(fullWitness *witness.Witness) (Proof, error) {
+	if fullWitness == nil {
+		return nil, fmt.Errorf("%w: nil witness", witness.ErrInvalidWitness)
+	}
+	return f(fullWitness)
+}
+
+// NewProver returns a Prover for r1cs and pk; opts apply to every proof it generates.
+//
+// backend.WithCheckpoint is not supported, since the proofs of a Prover would overwrite each
+// other's checkpoints.
+func NewProver(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, opts ...backend.ProverOption) (Prover, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if opt.CheckpointPath != "" {
+		return nil, errors.New("checkpoints are not supported by Prover")
+	}
+
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		p := groth16_bls12377.NewProver(_r1cs, pk.(*groth16_bls12377.ProvingKey), opt)
+		return proverFunc(func(fullWitness *witness.Witness) (Proof, error) {
+			w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			return p.Prove(*w)
+		}), nil
+	case *backend_bls12381.R1CS:
+		p := groth16_bls12381.NewProver(_r1cs, pk.(*groth16_bls12381.ProvingKey), opt)
+		return proverFunc(func(fullWitness *witness.Witness) (Proof, error) {
+			w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			return p.Prove(*w)
+		}), nil
+	case *backend_bn254.R1CS:
+		p := groth16_bn254.NewProver(_r1cs, pk.(*groth16_bn254.ProvingKey), opt)
+		return proverFunc(func(fullWitness *witness.Witness) (Proof, error) {
+			w, ok := fullWitness.Vector.(*witness_bn254.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			return p.Prove(*w)
+		}), nil
+	case *backend_bw6761.R1CS:
+		p := groth16_bw6761.NewProver(_r1cs, pk.(*groth16_bw6761.ProvingKey), opt)
+		return proverFunc(func(fullWitness *witness.Witness) (Proof, error) {
+			w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			return p.Prove(*w)
+		}), nil
+	case *backend_bls24315.R1CS:
+		p := groth16_bls24315.NewProver(_r1cs, pk.(*groth16_bls24315.ProvingKey), opt)
+		return proverFunc(func(fullWitness *witness.Witness) (Proof, error) {
+			w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			return p.Prove(*w)
+		}), nil
+	case *backend_bw6633.R1CS:
+		p := groth16_bw6633.NewProver(_r1cs, pk.(*groth16_bw6633.ProvingKey), opt)
+		return proverFunc(func(fullWitness *witness.Witness) (Proof, error) {
+			w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
+			if !ok {
+				return nil, witness.ErrInvalidWitness
+			}
+			return p.Prove(*w)
+		}), nil
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// BatchProve generates a proof for each of witnesses, against the same constraint system and
+// proving key.
+//
+// The witnesses are solved, and their H polynomials computed over the FFT domain of pk, two at a
+// time (see backend.ProverConfig.BatchTasks). Then each multi-exponentiation is computed once for
+// all the witnesses: a single pass over the points of pk adds each of them to the buckets of
+// every witness. All the solutions are held in memory until then, so that a batch needs about
+// len(witnesses) times the memory of Prove.
+//
+// If some witnesses can't be proven, the returned error is a *backend.BatchError; the
+// corresponding proofs are nil, and the other ones are returned. A nil witness is such an
+// error, witness.ErrInvalidWitness.
+func BatchProve(r1cs frontend.CompiledConstraintSystem, pk ProvingKey, witnesses []*witness.Witness, opts ...backend.ProverOption) ([]Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if opt.CheckpointPath != "" {
+		return nil, errors.New("checkpoints are not supported by BatchProve")
+	}
+
+	res := make([]Proof, len(witnesses))
+	errs := make([]error, len(witnesses))
+	switch _r1cs := r1cs.(type) {
+	case *backend_bls12377.R1CS:
+		var ws []witness_bls12377.Witness
+		indexes := filterWitnesses(witnesses, errs, func(v witness.Vector) bool {
+			w, ok := v.(*witness_bls12377.Witness)
+			if ok {
+				ws = append(ws, *w)
+			}
+			return ok
+		})
+		proofs, batchErrs := groth16_bls12377.NewProver(_r1cs, pk.(*groth16_bls12377.ProvingKey), opt).BatchProve(ws)
+		for k, i := range indexes {
+			if errs[i] = batchErrs[k]; errs[i] == nil {
+				res[i] = proofs[k]
+			}
+		}
+	case *backend_bls12381.R1CS:
+		var ws []witness_bls12381.Witness
+		indexes := filterWitnesses(witnesses, errs, func(v witness.Vector) bool {
+			w, ok := v.(*witness_bls12381.Witness)
+			if ok {
+				ws = append(ws, *w)
+			}
+			return ok
+		})
+		proofs, batchErrs := groth16_bls12381.NewProver(_r1cs, pk.(*groth16_bls12381.ProvingKey), opt).BatchProve(ws)
+		for k, i := range indexes {
+			if errs[i] = batchErrs[k]; errs[i] == nil {
+				res[i] = proofs[k]
+			}
+		}
+	case *backend_bn254.R1CS:
+		var ws []witness_bn254.Witness
+		indexes := filterWitnesses(witnesses, errs, func(v witness.Vector) bool {
+			w, ok := v.(*witness_bn254.Witness)
+			if ok {
+				ws = append(ws, *w)
+			}
+			return ok
+		})
+		proofs, batchErrs := groth16_bn254.NewProver(_r1cs, pk.(*groth16_bn254.ProvingKey), opt).BatchProve(ws)
+		for k, i := range indexes {
+			if errs[i] = batchErrs[k]; errs[i] == nil {
+				res[i] = proofs[k]
+			}
+		}
+	case *backend_bw6761.R1CS:
+		var ws []witness_bw6761.Witness
+		indexes := filterWitnesses(witnesses, errs, func(v witness.Vector) bool {
+			w, ok := v.(*witness_bw6761.Witness)
+			if ok {
+				ws = append(ws, *w)
+			}
+			return ok
+		})
+		proofs, batchErrs := groth16_bw6761.NewProver(_r1cs, pk.(*groth16_bw6761.ProvingKey), opt).BatchProve(ws)
+		for k, i := range indexes {
+			if errs[i] = batchErrs[k]; errs[i] == nil {
+				res[i] = proofs[k]
+			}
+		}
+	case *backend_bls24315.R1CS:
+		var ws []witness_bls24315.Witness
+		indexes := filterWitnesses(witnesses, errs, func(v witness.Vector) bool {
+			w, ok := v.(*witness_bls24315.Witness)
+			if ok {
+				ws = append(ws, *w)
+			}
+			return ok
+		})
+		proofs, batchErrs := groth16_bls24315.NewProver(_r1cs, pk.(*groth16_bls24315.ProvingKey), opt).BatchProve(ws)
+		for k, i := range indexes {
+			if errs[i] = batchErrs[k]; errs[i] == nil {
+				res[i] = proofs[k]
+			}
+		}
+	case *backend_bw6633.R1CS:
+		var ws []witness_bw6633.Witness
+		indexes := filterWitnesses(witnesses, errs, func(v witness.Vector) bool {
+			w, ok := v.(*witness_bw6633.Witness)
+			if ok {
+				ws = append(ws, *w)
+			}
+			return ok
+		})
+		proofs, batchErrs := groth16_bw6633.NewProver(_r1cs, pk.(*groth16_bw6633.ProvingKey), opt).BatchProve(ws)
+		for k, i := range indexes {
+			if errs[i] = batchErrs[k]; errs[i] == nil {
+				res[i] = proofs[k]
+			}
+		}
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return res, &backend.BatchError{Errs: errs}
+		}
+	}
+	return res, nil
+}
+
+// filterWitnesses returns the indexes of the witnesses whose vector is accepted by add, and sets
+// the errors of the other ones in errs
+func filterWitnesses(witnesses []*witness.Witness, errs []error, add func(witness.Vector) bool) []int {
+	indexes := make([]int, 0, len(witnesses))
+	for i, w := range witnesses {
+		switch {
+		case w == nil:
+			errs[i] = fmt.Errorf("%w: nil witness", witness.ErrInvalidWitness)
+		case !add(w.Vector):
+			errs[i] = witness.ErrInvalidWitness
+		default:
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
@@ -0,0 +1,63 @@
+package groth16
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProver(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+		prover, err := NewProver(ccs, pk)
+		assert.NoError(err)
+
+		// a proof failing to solve must not corrupt the next ones
+		bad, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 42}, curve)
+		assert.NoError(err)
+		_, err = prover.Prove(bad)
+		assert.Error(err)
+
+		const n = 8
+		var wg sync.WaitGroup
+		errs := make([]error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				x := i + 1
+				w, err := frontend.NewWitness(&mpcCircuit{X: x, Y: x*x*x + 3*x + 5}, curve)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				var publicWitness *witness.Witness
+				if publicWitness, errs[i] = w.Public(); errs[i] != nil {
+					return
+				}
+				// prove twice, to reuse the pooled buffers
+				for j := 0; j < 2 && errs[i] == nil; j++ {
+					var proof Proof
+					if proof, errs[i] = prover.Prove(w); errs[i] == nil {
+						errs[i] = Verify(proof, vk, publicWitness)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			assert.NoError(err)
+		}
+	}
+}
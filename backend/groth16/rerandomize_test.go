@@ -0,0 +1,53 @@
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerandomize(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range ecc.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, w)
+		assert.NoError(err)
+
+		rerandomized, err := Rerandomize(proof, vk)
+		assert.NoError(err)
+		assert.NoError(Verify(rerandomized, vk, publicWitness))
+		assert.False(bytes.Equal(proofBytes(t, proof), proofBytes(t, rerandomized)), "rerandomized proof must differ")
+
+		// a rerandomized proof can be rerandomized again
+		again, err := Rerandomize(rerandomized, vk)
+		assert.NoError(err)
+		assert.NoError(Verify(again, vk, publicWitness))
+		assert.False(bytes.Equal(proofBytes(t, rerandomized), proofBytes(t, again)), "rerandomized proof must differ")
+
+		// it still proves the same statement only
+		wrong, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 42}, curve, frontend.PublicOnly())
+		assert.NoError(err)
+		assert.Error(Verify(rerandomized, vk, wrong))
+	}
+}
+
+func proofBytes(t *testing.T, proof Proof) []byte {
+	var buf bytes.Buffer
+	_, err := proof.WriteTo(&buf)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
@@ -0,0 +1,46 @@
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupTo(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+
+		var pkBuf, vkBuf bytes.Buffer
+		assert.NoError(SetupTo(ccs, &pkBuf, &vkBuf))
+		written := pkBuf.Bytes()
+
+		pk := NewProvingKey(curve)
+		_, err = pk.ReadFrom(bytes.NewReader(written))
+		assert.NoError(err)
+		vk := NewVerifyingKey(curve)
+		_, err = vk.ReadFrom(&vkBuf)
+		assert.NoError(err)
+
+		// the streamed key has the WriteRawTo layout
+		var raw bytes.Buffer
+		_, err = pk.WriteRawTo(&raw)
+		assert.NoError(err)
+		assert.Equal(written, raw.Bytes())
+
+		w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, curve)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, w)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+	}
+}
@@ -0,0 +1,84 @@
+package groth16
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+type snarkjsCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (c *snarkjsCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), api.Add(c.Y, c.Z))
+	return nil
+}
+
+func TestSnarkJS(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &snarkjsCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs)
+		assert.NoError(err)
+
+		fullWitness, err := frontend.NewWitness(&snarkjsCircuit{X: 3, Y: 20, Z: 7}, curve)
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, fullWitness)
+		assert.NoError(err)
+
+		// export
+		vkJSON, err := vk.MarshalSnarkJS()
+		assert.NoError(err)
+		proofJSON, err := proof.MarshalSnarkJS()
+		assert.NoError(err)
+		publicJSON, err := fullWitness.MarshalSnarkJS()
+		assert.NoError(err)
+
+		var public []string
+		assert.NoError(json.Unmarshal(publicJSON, &public))
+		assert.Equal([]string{"20", "7"}, public)
+
+		var v map[string]interface{}
+		assert.NoError(json.Unmarshal(vkJSON, &v))
+		assert.Equal("groth16", v["protocol"])
+		assert.Equal(float64(2), v["nPublic"])
+		assert.Len(v["IC"], 3)
+
+		// import
+		vk2 := NewVerifyingKey(curve)
+		assert.NoError(vk2.UnmarshalSnarkJS(vkJSON))
+		proof2 := NewProof(curve)
+		assert.NoError(proof2.UnmarshalSnarkJS(proofJSON))
+		publicWitness, err := witness.New(curve, nil)
+		assert.NoError(err)
+		assert.NoError(publicWitness.UnmarshalSnarkJS(publicJSON))
+
+		assert.NoError(Verify(proof2, vk2, publicWitness))
+
+		// wrong public input
+		assert.NoError(publicWitness.UnmarshalSnarkJS([]byte(`["21", "7"]`)))
+		assert.Error(Verify(proof2, vk2, publicWitness))
+
+		// the curve must match
+		other := ecc.BN254
+		if curve == ecc.BN254 {
+			other = ecc.BLS12_381
+		}
+		assert.Error(NewProof(other).UnmarshalSnarkJS(proofJSON))
+	}
+
+	// other curves are not supported by snarkjs
+	_, err := NewProof(ecc.BW6_761).MarshalSnarkJS()
+	assert.Error(err)
+}
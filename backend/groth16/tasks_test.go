@@ -0,0 +1,68 @@
+package groth16
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNbTasks(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	for _, nbTasks := range []int{1, 2, 3} {
+		assert.NoError(ccs.IsSolved(w, backend.WithNbTasks(nbTasks)))
+		proof, err := Prove(ccs, pk, w, backend.WithNbTasks(nbTasks))
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+	}
+
+	_, err = Prove(ccs, pk, w, backend.WithNbTasks(0))
+	assert.Error(err)
+}
+
+func TestSetupNbTasks(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &mpcCircuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&mpcCircuit{X: 3, Y: 41}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	for _, nbTasks := range []int{1, 2, 3} {
+		pk, vk, err := Setup(ccs, backend.WithSetupNbTasks(nbTasks))
+		assert.NoError(err)
+		proof, err := Prove(ccs, pk, w)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+
+		var pkBuf, vkBuf bytes.Buffer
+		assert.NoError(SetupTo(ccs, &pkBuf, &vkBuf, backend.WithSetupNbTasks(nbTasks)))
+		pk, vk = NewProvingKey(ecc.BN254), NewVerifyingKey(ecc.BN254)
+		_, err = pk.ReadFrom(&pkBuf)
+		assert.NoError(err)
+		_, err = vk.ReadFrom(&vkBuf)
+		assert.NoError(err)
+		proof, err = Prove(ccs, pk, w)
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+	}
+
+	_, _, err = Setup(ccs, backend.WithSetupNbTasks(0))
+	assert.Error(err)
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/json"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// PhaseMetrics are the measurements of a phase of Prove
+type PhaseMetrics struct {
+	// Name of the phase, for example "solve", "fft h", "msm A" (groth16)
+	// or "quotient", "batch opening" (plonk)
+	Name string `json:"name"`
+
+	// Duration of the phase; phases may overlap, as some run concurrently
+	Duration time.Duration `json:"durationNs"`
+
+	// NbElements is the size of the phase: number of constraints for the solver, size of the
+	// domain for FFTs, number of points for multi-exponentiations
+	NbElements int `json:"nbElements"`
+
+	// AllocatedBytes is the number of bytes allocated on the heap during the phase, by the
+	// whole process
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+}
+
+// ProverObserver receives the measurements of the phases of Prove. ObservePhase may be
+// called concurrently.
+type ProverObserver interface {
+	ObservePhase(m PhaseMetrics)
+}
+
+// WithObserver is a prover option that sets an observer, notified at the end of each phase
+// of Prove. Measuring the allocated bytes briefly stops the world: the observer should not
+// be set on latency critical provers, unless needed.
+func WithObserver(observer ProverObserver) ProverOption {
+	return func(opt *ProverConfig) error {
+		opt.Observer = observer
+		return nil
+	}
+}
+
+// StartPhase is called by the provers at the beginning of a phase; the returned function
+// notifies the observer at its end, with the number of elements processed. It is a no-op
+// if no observer is set.
+func (cfg ProverConfig) StartPhase(name string) func(nbElements int) {
+	if cfg.Observer == nil {
+		return func(int) {}
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	allocated := m.TotalAlloc
+	start := time.Now()
+
+	return func(nbElements int) {
+		duration := time.Since(start)
+		runtime.ReadMemStats(&m)
+		cfg.Observer.ObservePhase(PhaseMetrics{
+			Name:           name,
+			Duration:       duration,
+			NbElements:     nbElements,
+			AllocatedBytes: m.TotalAlloc - allocated,
+		})
+	}
+}
+
+// Report is a ProverObserver recording the phases of one or several calls to Prove,
+// in the order in which they end
+type Report struct {
+	lock   sync.Mutex
+	phases []PhaseMetrics
+}
+
+// ObservePhase implements ProverObserver
+func (r *Report) ObservePhase(m PhaseMetrics) {
+	r.lock.Lock()
+	r.phases = append(r.phases, m)
+	r.lock.Unlock()
+}
+
+// Phases returns a copy of the recorded phases
+func (r *Report) Phases() []PhaseMetrics {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]PhaseMetrics(nil), r.phases...)
+}
+
+// MarshalJSON implements json.Marshaler
+//
+//	{"phases": [{"name": "solve", "durationNs": 1234, "nbElements": 42, "allocatedBytes": 4096}, ...]}
+func (r *Report) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Phases []PhaseMetrics `json:"phases"`
+	}{r.Phases()})
+}
+
+// WriteJSON writes the JSON encoding of the report to w
+func (r *Report) WriteJSON(w io.Writer) error {
+	data, err := r.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk
+
+import (
+	"io"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+
+	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	cs_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	cs_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	cs_bn254 "github.com/consensys/gnark/internal/backend/bn254/cs"
+	cs_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	cs_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+
+	plonk_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/plonk"
+	plonk_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonk"
+	plonk_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/plonk"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	plonk_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/plonk"
+	plonk_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/plonk"
+
+	witness_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/witness"
+	witness_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/witness"
+	witness_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/witness"
+	witness_bn254 "github.com/consensys/gnark/internal/backend/bn254/witness"
+	witness_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/witness"
+	witness_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/witness"
+)
+
+// Resume completes a proof interrupted after Prove saved a checkpoint (see
+// backend.WithCheckpoint), reading the checkpoint from r. The rounds of the prover held in the
+// checkpoint are not computed again; the proof is the same as if Prove had not been interrupted.
+//
+// ccs, pk and fullWitness must be those given to Prove: if the checkpoint was saved for another
+// proving key or witness, Resume returns backend.ErrCheckpointMismatch. opts apply to the
+// remaining rounds, and may set a checkpoint again.
+func Resume(ccs frontend.CompiledConstraintSystem, pk ProvingKey, fullWitness *witness.Witness, r io.Reader, opts ...backend.ProverOption) (Proof, error) {
+
+	// apply options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bn254.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonk_bn254.Resume(tccs, pk.(*plonk_bn254.ProvingKey), *w, r, opt)
+
+	case *cs_bls12381.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bls12381.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonk_bls12381.Resume(tccs, pk.(*plonk_bls12381.ProvingKey), *w, r, opt)
+
+	case *cs_bls12377.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bls12377.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonk_bls12377.Resume(tccs, pk.(*plonk_bls12377.ProvingKey), *w, r, opt)
+
+	case *cs_bw6761.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bw6761.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonk_bw6761.Resume(tccs, pk.(*plonk_bw6761.ProvingKey), *w, r, opt)
+
+	case *cs_bw6633.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bw6633.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonk_bw6633.Resume(tccs, pk.(*plonk_bw6633.ProvingKey), *w, r, opt)
+
+	case *cs_bls24315.SparseR1CS:
+		w, ok := fullWitness.Vector.(*witness_bls24315.Witness)
+		if !ok {
+			return nil, witness.ErrInvalidWitness
+		}
+		return plonk_bls24315.Resume(tccs, pk.(*plonk_bls24315.ProvingKey), *w, r, opt)
+
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+}
@@ -0,0 +1,75 @@
+package plonk
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+// cancelOnCheckpoint cancels a proof once it saved its first checkpoint
+type cancelOnCheckpoint struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelOnCheckpoint) ObservePhase(m backend.PhaseMetrics) {
+	if m.Name == "checkpoint" {
+		c.cancel()
+	}
+}
+
+func TestResume(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs, srs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	path := filepath.Join(t.TempDir(), "proof.checkpoint")
+
+	// interrupt the proof after its first round
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = Prove(ccs, pk, w, backend.WithCheckpoint(path), backend.WithContext(ctx), backend.WithObserver(cancelOnCheckpoint{cancel}))
+	assert.ErrorIs(err, context.Canceled)
+
+	resume := func() (Proof, error) {
+		f, err := os.Open(path)
+		assert.NoError(err)
+		defer f.Close()
+		return Resume(ccs, pk, w, f, backend.WithCheckpoint(path))
+	}
+	proof, err := resume()
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	// the checkpoint now holds all the rounds before the openings
+	proof, err = resume()
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	// a checkpoint is bound to its witness
+	other, err := frontend.NewWitness(&srsCircuit{X: 2, Y: 15}, ecc.BN254)
+	assert.NoError(err)
+	f, err := os.Open(path)
+	assert.NoError(err)
+	defer f.Close()
+	_, err = Resume(ccs, pk, other, f)
+	assert.ErrorIs(err, backend.ErrCheckpointMismatch)
+
+	_, err = NewProver(ccs, pk, backend.WithCheckpoint(path))
+	assert.Error(err)
+}
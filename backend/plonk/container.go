@@ -0,0 +1,120 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// The WriteTo and WriteRawTo methods of the PlonK keys and proofs write them in a
+// container (see gnark/io), which records their kind, curve and the format version, and
+// a checksum; their ReadFrom and UnsafeReadFrom methods read such a container, or the
+// bare encoding written by previous versions of gnark.
+//
+// The readers below instantiate the object for the curve of the container; if curveID is
+// not ecc.UNKNOWN, a container for another curve is rejected with a *gnarkio.MismatchError,
+// and a bare encoding is read on curveID.
+
+// WriteProvingKey writes pk to w in a container, as pk.WriteTo does
+func WriteProvingKey(w io.Writer, pk ProvingKey) (int64, error) {
+	return pk.WriteTo(w)
+}
+
+// ReadProvingKey reads a ProvingKey written by WriteProvingKey
+func ReadProvingKey(r io.Reader, curveID ecc.ID) (ProvingKey, int64, error) {
+	o, n, err := readObject(r, gnarkio.KindProvingKey, curveID, func(curveID ecc.ID) io.ReaderFrom {
+		return NewProvingKey(curveID)
+	})
+	if err != nil {
+		return nil, n, err
+	}
+	return o.(ProvingKey), n, nil
+}
+
+// WriteVerifyingKey writes vk to w in a container, as vk.WriteTo does
+func WriteVerifyingKey(w io.Writer, vk VerifyingKey) (int64, error) {
+	return vk.WriteTo(w)
+}
+
+// ReadVerifyingKey reads a VerifyingKey written by WriteVerifyingKey
+func ReadVerifyingKey(r io.Reader, curveID ecc.ID) (VerifyingKey, int64, error) {
+	o, n, err := readObject(r, gnarkio.KindVerifyingKey, curveID, func(curveID ecc.ID) io.ReaderFrom {
+		return NewVerifyingKey(curveID)
+	})
+	if err != nil {
+		return nil, n, err
+	}
+	return o.(VerifyingKey), n, nil
+}
+
+// WriteProof writes proof to w in a container, as proof.WriteTo does
+func WriteProof(w io.Writer, proof Proof) (int64, error) {
+	return proof.WriteTo(w)
+}
+
+// ReadProof reads a Proof written by WriteProof
+func ReadProof(r io.Reader, curveID ecc.ID) (Proof, int64, error) {
+	o, n, err := readObject(r, gnarkio.KindProof, curveID, func(curveID ecc.ID) io.ReaderFrom {
+		return NewProof(curveID)
+	})
+	if err != nil {
+		return nil, n, err
+	}
+	return o.(Proof), n, nil
+}
+
+// WriteCS writes the SparseR1CS to w in a container
+func WriteCS(w io.Writer, ccs frontend.CompiledConstraintSystem) (int64, error) {
+	return gnarkio.WriteContainer(w, header(gnarkio.KindConstraintSystem, ccs.CurveID()), ccs)
+}
+
+// ReadCS reads a SparseR1CS written by WriteCS
+func ReadCS(r io.Reader, curveID ecc.ID) (frontend.CompiledConstraintSystem, int64, error) {
+	var ccs frontend.CompiledConstraintSystem
+	_, n, err := gnarkio.ReadContainer(r, header(gnarkio.KindConstraintSystem, curveID), func(h gnarkio.Header, r io.Reader) (int64, error) {
+		ccs = NewCS(h.Curve)
+		return ccs.ReadFrom(r)
+	})
+	if err != nil {
+		return nil, n, err
+	}
+	return ccs, n, nil
+}
+
+// readObject reads an object of the given kind, instantiated by newObject for the curve
+// of its container, or for curveID if r holds its bare encoding
+func readObject(r io.Reader, kind gnarkio.Kind, curveID ecc.ID, newObject func(ecc.ID) io.ReaderFrom) (io.ReaderFrom, int64, error) {
+	h, r, err := gnarkio.ReadHeader(r, header(kind, curveID))
+	if err == gnarkio.ErrInvalidMagic && curveID != ecc.UNKNOWN {
+		h.Curve = curveID
+	} else if err != nil {
+		return nil, 0, err
+	}
+	o := newObject(h.Curve)
+	n, err := o.ReadFrom(r)
+	if err != nil {
+		return nil, n, err
+	}
+	return o, n, nil
+}
+
+func header(kind gnarkio.Kind, curveID ecc.ID) gnarkio.Header {
+	return gnarkio.Header{Kind: kind, Backend: backend.PLONK, Curve: curveID}
+}
@@ -0,0 +1,117 @@
+package plonk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainer(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs, srs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	proof, err := Prove(ccs, pk, w)
+	assert.NoError(err)
+
+	var ccsBuf, pkBuf, vkBuf, proofBuf bytes.Buffer
+	_, err = WriteCS(&ccsBuf, ccs)
+	assert.NoError(err)
+	_, err = WriteProvingKey(&pkBuf, pk)
+	assert.NoError(err)
+	_, err = WriteVerifyingKey(&vkBuf, vk)
+	assert.NoError(err)
+	_, err = WriteProof(&proofBuf, proof)
+	assert.NoError(err)
+	proofBytes := append([]byte(nil), proofBuf.Bytes()...)
+
+	ccs, _, err = ReadCS(&ccsBuf, ecc.BN254)
+	assert.NoError(err)
+	pk, _, err = ReadProvingKey(&pkBuf, ecc.UNKNOWN)
+	assert.NoError(err)
+	assert.NoError(pk.InitKZG(srs))
+	vk, _, err = ReadVerifyingKey(&vkBuf, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(vk.InitKZG(srs))
+	proof, _, err = ReadProof(&proofBuf, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+	proof, err = Prove(ccs, pk, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	_, _, err = ReadProof(bytes.NewReader(proofBytes), ecc.BW6_761)
+	var mismatch *gnarkio.MismatchError
+	assert.True(errors.As(err, &mismatch))
+	assert.Equal("curve", mismatch.Field)
+
+	corrupted := append([]byte(nil), proofBytes...)
+	corrupted[len(corrupted)-1] ^= 1
+	_, _, err = ReadProof(bytes.NewReader(corrupted), ecc.BN254)
+	assert.ErrorIs(err, gnarkio.ErrChecksumMismatch)
+}
+
+// TestReadLegacy reads the bare encodings written before containers, in which the verifying
+// keys don't encode the coset shift. They were generated by Setup(ccs, srs) and Prove for
+// srsCircuit on BN254, with the SRS of secret 42 of size ecc.NextPowerOfTwo(SRSSize(ccs)).
+func TestReadLegacy(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	f, err := os.Open("testdata/v0_bn254.vk")
+	assert.NoError(err)
+	defer f.Close()
+	vk, _, err := ReadVerifyingKey(f, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(vk.InitKZG(srs))
+
+	f, err = os.Open("testdata/v0_bn254.proof")
+	assert.NoError(err)
+	defer f.Close()
+	proof := NewProof(ecc.BN254)
+	_, err = proof.ReadFrom(f)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	f, err = os.Open("testdata/v0_bn254.pk")
+	assert.NoError(err)
+	defer f.Close()
+	pk, _, err := ReadProvingKey(f, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(pk.InitKZG(srs))
+	proof, err = Prove(ccs, pk, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	// the curve of a bare encoding must be given
+	_, err = f.Seek(0, io.SeekStart)
+	assert.NoError(err)
+	_, _, err = ReadProvingKey(f, ecc.UNKNOWN)
+	assert.ErrorIs(err, gnarkio.ErrInvalidMagic)
+}
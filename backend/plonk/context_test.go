@@ -0,0 +1,40 @@
+package plonk
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = SetupContext(ctx, ccs, srs)
+	assert.ErrorIs(err, context.Canceled)
+
+	pk, vk, err := SetupContext(context.Background(), ccs, srs)
+	assert.NoError(err)
+	_, err = Prove(ccs, pk, w, backend.WithContext(ctx))
+	assert.ErrorIs(err, context.Canceled)
+
+	proof, err := Prove(ccs, pk, w, backend.WithContext(context.Background()))
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk
+
+import (
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gnarkio "github.com/consensys/gnark/io"
+)
+
+// mappableProvingKey is implemented by the curve typed proving keys
+type mappableProvingKey interface {
+	WriteMappedTo(w io.Writer) (int64, error)
+	UnsafeMapFrom(data []byte) error
+}
+
+// WriteMappedProvingKey writes pk to w in a raw layout which MapProvingKey memory-maps (see
+// gnarkio.MappedWriter). The points are written uncompressed, as they are represented in memory:
+// the output can only be mapped on machines with the same byte order.
+func WriteMappedProvingKey(w io.Writer, pk ProvingKey) (int64, error) {
+	return pk.(mappableProvingKey).WriteMappedTo(w)
+}
+
+// MapProvingKey memory-maps the proving key written by WriteMappedProvingKey in the file at path.
+//
+// Prove reads the polynomials of the key and the points of its KZG SRS from the mapping as it
+// uses them, and the operating system can evict them from memory: proving works on machines with
+// less memory than the size of the key (on unix systems, see gnarkio.MapFile). The key must not
+// be used once the returned io.Closer is closed.
+//
+// As with UnsafeReadFrom, the points are not checked.
+func MapProvingKey(path string, curveID ecc.ID) (ProvingKey, io.Closer, error) {
+	f, err := gnarkio.MapFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	pk := NewProvingKey(curveID)
+	if err := pk.(mappableProvingKey).UnsafeMapFrom(f.Bytes()); err != nil {
+		_ = f.Close()
+		return nil, nil, err
+	}
+	return pk, f, nil
+}
@@ -0,0 +1,50 @@
+package plonk
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapProvingKey(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs, srs)
+	assert.NoError(err)
+
+	path := filepath.Join(t.TempDir(), "pk")
+	f, err := os.Create(path)
+	assert.NoError(err)
+	_, err = WriteMappedProvingKey(f, pk)
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	mapped, closer, err := MapProvingKey(path, ecc.BN254)
+	assert.NoError(err)
+	defer closer.Close()
+
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	proof, err := Prove(ccs, mapped, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	_, _, err = MapProvingKey(path, ecc.BLS12_381)
+	var mismatch *gnarkio.MismatchError
+	assert.True(errors.As(err, &mismatch))
+}
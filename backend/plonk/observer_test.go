@@ -0,0 +1,38 @@
+package plonk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	pk, _, err := Setup(ccs, srs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+
+	var report backend.Report
+	_, err = Prove(ccs, pk, w, backend.WithObserver(&report))
+	assert.NoError(err)
+
+	var names []string
+	for _, p := range report.Phases() {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch([]string{
+		"solve", "fft lro", "commit lro", "permutation z", "fft constraints", "quotient",
+		"commit h", "open z", "linearized polynomial", "batch opening",
+	}, names)
+}
@@ -20,6 +20,7 @@
 package plonk
 
 import (
+	"context"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -27,6 +28,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/plonk"
+	gnarkio "github.com/consensys/gnark/io"
 
 	"github.com/consensys/gnark/backend/witness"
 	cs_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/cs"
@@ -68,6 +70,9 @@ func init() {
 type Proof interface {
 	io.WriterTo
 	io.ReaderFrom
+	gnarkio.WriterRawTo
+	gnarkio.UnsafeReaderFrom
+	CurveID() ecc.ID
 }
 
 // ProvingKey represents a plonk ProvingKey
@@ -76,6 +81,9 @@ type Proof interface {
 type ProvingKey interface {
 	io.WriterTo
 	io.ReaderFrom
+	gnarkio.WriterRawTo
+	gnarkio.UnsafeReaderFrom
+	CurveID() ecc.ID
 	InitKZG(srs kzg.SRS) error
 	VerifyingKey() interface{}
 }
@@ -86,32 +94,81 @@ type ProvingKey interface {
 type VerifyingKey interface {
 	io.WriterTo
 	io.ReaderFrom
+	gnarkio.WriterRawTo
+	gnarkio.UnsafeReaderFrom
+	CurveID() ecc.ID
 	InitKZG(srs kzg.SRS) error
 	NbPublicWitness() int // number of elements expected in the public witness
 }
 
 // Setup prepares the public data associated to a circuit + public inputs.
-func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS) (ProvingKey, VerifyingKey, error) {
+func Setup(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
+	return SetupContext(context.Background(), ccs, kzgSRS, opts...)
+}
+
+// SetupContext behaves like Setup, and returns ctx.Err() if ctx is done before the
+// setup completes
+func SetupContext(ctx context.Context, ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS, opts ...backend.SetupOption) (ProvingKey, VerifyingKey, error) {
+	opt, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	switch tccs := ccs.(type) {
 	case *cs_bn254.SparseR1CS:
-		return plonk_bn254.Setup(tccs, kzgSRS.(*kzg_bn254.SRS))
+		return plonk_bn254.SetupContext(ctx, tccs, kzgSRS.(*kzg_bn254.SRS), opt)
 	case *cs_bls12381.SparseR1CS:
-		return plonk_bls12381.Setup(tccs, kzgSRS.(*kzg_bls12381.SRS))
+		return plonk_bls12381.SetupContext(ctx, tccs, kzgSRS.(*kzg_bls12381.SRS), opt)
 	case *cs_bls12377.SparseR1CS:
-		return plonk_bls12377.Setup(tccs, kzgSRS.(*kzg_bls12377.SRS))
+		return plonk_bls12377.SetupContext(ctx, tccs, kzgSRS.(*kzg_bls12377.SRS), opt)
 	case *cs_bw6761.SparseR1CS:
-		return plonk_bw6761.Setup(tccs, kzgSRS.(*kzg_bw6761.SRS))
+		return plonk_bw6761.SetupContext(ctx, tccs, kzgSRS.(*kzg_bw6761.SRS), opt)
 	case *cs_bls24315.SparseR1CS:
-		return plonk_bls24315.Setup(tccs, kzgSRS.(*kzg_bls24315.SRS))
+		return plonk_bls24315.SetupContext(ctx, tccs, kzgSRS.(*kzg_bls24315.SRS), opt)
 	case *cs_bw6633.SparseR1CS:
-		return plonk_bw6633.Setup(tccs, kzgSRS.(*kzg_bw6633.SRS))
+		return plonk_bw6633.SetupContext(ctx, tccs, kzgSRS.(*kzg_bw6633.SRS), opt)
 	default:
 		panic("unrecognized SparseR1CS curve type")
 	}
 
 }
 
+// SetupTo behaves like Setup, but writes the ProvingKey to pkWriter and the VerifyingKey to
+// vkWriter instead of returning them. The keys are encoded as with WriteRawTo, and can be
+// read back with ReadFrom (the KZG SRS must then be set with InitKZG).
+//
+// The polynomials of the proving key are computed, committed to and written one at a time,
+// which bounds the memory needed to setup very large circuits.
+func SetupTo(ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS, pkWriter, vkWriter io.Writer, opts ...backend.SetupOption) error {
+	return SetupToContext(context.Background(), ccs, kzgSRS, pkWriter, vkWriter, opts...)
+}
+
+// SetupToContext behaves like SetupTo, and returns ctx.Err() if ctx is done before the
+// setup completes
+func SetupToContext(ctx context.Context, ccs frontend.CompiledConstraintSystem, kzgSRS kzg.SRS, pkWriter, vkWriter io.Writer, opts ...backend.SetupOption) error {
+	opt, err := backend.NewSetupConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	switch tccs := ccs.(type) {
+	case *cs_bn254.SparseR1CS:
+		return plonk_bn254.SetupTo(ctx, tccs, kzgSRS.(*kzg_bn254.SRS), pkWriter, vkWriter, opt)
+	case *cs_bls12381.SparseR1CS:
+		return plonk_bls12381.SetupTo(ctx, tccs, kzgSRS.(*kzg_bls12381.SRS), pkWriter, vkWriter, opt)
+	case *cs_bls12377.SparseR1CS:
+		return plonk_bls12377.SetupTo(ctx, tccs, kzgSRS.(*kzg_bls12377.SRS), pkWriter, vkWriter, opt)
+	case *cs_bw6761.SparseR1CS:
+		return plonk_bw6761.SetupTo(ctx, tccs, kzgSRS.(*kzg_bw6761.SRS), pkWriter, vkWriter, opt)
+	case *cs_bls24315.SparseR1CS:
+		return plonk_bls24315.SetupTo(ctx, tccs, kzgSRS.(*kzg_bls24315.SRS), pkWriter, vkWriter, opt)
+	case *cs_bw6633.SparseR1CS:
+		return plonk_bw6633.SetupTo(ctx, tccs, kzgSRS.(*kzg_bw6633.SRS), pkWriter, vkWriter, opt)
+	default:
+		panic("unrecognized SparseR1CS curve type")
+	}
+}
+
 // Prove generates PLONK proof from a circuit, associated preprocessed public data, and the witness
 // if the force flag is set:
 // 	will executes all the prover computations, even if the witness is invalid
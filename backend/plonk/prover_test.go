@@ -0,0 +1,64 @@
+package plonk
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProver(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs, srs)
+	assert.NoError(err)
+	prover, err := NewProver(ccs, pk)
+	assert.NoError(err)
+
+	// a proof failing to solve must not corrupt the next ones
+	bad, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 42}, ecc.BN254)
+	assert.NoError(err)
+	_, err = prover.Prove(bad)
+	assert.Error(err)
+
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			x := i + 1
+			w, err := frontend.NewWitness(&srsCircuit{X: x, Y: x*x*x + x + 5}, ecc.BN254)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			var publicWitness *witness.Witness
+			if publicWitness, errs[i] = w.Public(); errs[i] != nil {
+				return
+			}
+			// prove twice, to reuse the pooled buffers
+			for j := 0; j < 2 && errs[i] == nil; j++ {
+				var proof Proof
+				if proof, errs[i] = prover.Prove(w); errs[i] == nil {
+					errs[i] = Verify(proof, vk, publicWitness)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(err)
+	}
+}
@@ -0,0 +1,53 @@
+package plonk
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupTo(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+
+	var pkBuf, vkBuf bytes.Buffer
+	assert.NoError(SetupTo(ccs, srs, &pkBuf, &vkBuf))
+
+	// the PlonK setup is deterministic: the streamed keys match the in-memory ones
+	pk, vk, err := Setup(ccs, srs)
+	assert.NoError(err)
+	var pkRaw, vkRaw bytes.Buffer
+	_, err = pk.WriteRawTo(&pkRaw)
+	assert.NoError(err)
+	_, err = vk.WriteRawTo(&vkRaw)
+	assert.NoError(err)
+	assert.Equal(pkRaw.Bytes(), pkBuf.Bytes())
+	assert.Equal(vkRaw.Bytes(), vkBuf.Bytes())
+
+	pkRead := NewProvingKey(ecc.BN254)
+	_, err = pkRead.ReadFrom(&pkBuf)
+	assert.NoError(err)
+	assert.NoError(pkRead.InitKZG(srs))
+	vkRead := NewVerifyingKey(ecc.BN254)
+	_, err = vkRead.ReadFrom(&vkBuf)
+	assert.NoError(err)
+	assert.NoError(vkRead.InitKZG(srs))
+
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	proof, err := Prove(ccs, pkRead, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vkRead, publicWitness))
+}
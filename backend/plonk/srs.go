@@ -0,0 +1,179 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plonk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+
+	groth16_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/groth16"
+	groth16_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/groth16"
+	groth16_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/groth16"
+	groth16_bn254 "github.com/consensys/gnark/internal/backend/bn254/groth16"
+	groth16_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/groth16"
+	groth16_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/groth16"
+
+	plonk_bls12377 "github.com/consensys/gnark/internal/backend/bls12-377/plonk"
+	plonk_bls12381 "github.com/consensys/gnark/internal/backend/bls12-381/plonk"
+	plonk_bls24315 "github.com/consensys/gnark/internal/backend/bls24-315/plonk"
+	plonk_bn254 "github.com/consensys/gnark/internal/backend/bn254/plonk"
+	plonk_bw6633 "github.com/consensys/gnark/internal/backend/bw6-633/plonk"
+	plonk_bw6761 "github.com/consensys/gnark/internal/backend/bw6-761/plonk"
+)
+
+// The KZG SRS used by Setup is made of powers of a secret τ, which must be generated
+// through a MPC: a powers of tau ceremony. The functions below build a SRS, truncated
+// to the size needed by a constraint system, from:
+//
+//	- the final state of a ceremony run with gnark (groth16.InitPhase1, Phase1.Contribute
+//	  and groth16.VerifyPhase1), the same ceremony can be used for Groth16 and PlonK;
+//	- a snarkjs .ptau file (BN254 and BLS12-381);
+//	- a response file of the perpetual powers of tau ceremony (BN254).
+//
+// The points are checked to be powers of the same τ. The contributions of the imported
+// files are not verified.
+
+// SRSSize returns the number of powers of τ in G1 needed by Setup for the constraint system
+func SRSSize(ccs frontend.CompiledConstraintSystem) int {
+	_, _, public := ccs.GetNbVariables()
+	sizeSystem := ccs.GetNbConstraints() + public
+	return int(ecc.NextPowerOfTwo(uint64(sizeSystem))) + 3
+}
+
+// NewSRS returns the KZG SRS for the constraint system from the final state of a
+// powers of tau ceremony, verified with groth16.VerifyPhase1
+func NewSRS(ccs frontend.CompiledConstraintSystem, phase1 groth16.Phase1) (kzg.SRS, error) {
+	if phase1.CurveID() != ccs.CurveID() {
+		return nil, errors.New("the powers of tau and the constraint system are defined on different curves")
+	}
+	size := SRSSize(ccs)
+	switch _phase1 := phase1.(type) {
+	case *groth16_bn254.Phase1:
+		srs, err := plonk_bn254.NewSRS(_phase1.Parameters.G1.Tau, _phase1.Parameters.G2.Tau, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bls12381.Phase1:
+		srs, err := plonk_bls12381.NewSRS(_phase1.Parameters.G1.Tau, _phase1.Parameters.G2.Tau, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bls12377.Phase1:
+		srs, err := plonk_bls12377.NewSRS(_phase1.Parameters.G1.Tau, _phase1.Parameters.G2.Tau, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bw6761.Phase1:
+		srs, err := plonk_bw6761.NewSRS(_phase1.Parameters.G1.Tau, _phase1.Parameters.G2.Tau, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bls24315.Phase1:
+		srs, err := plonk_bls24315.NewSRS(_phase1.Parameters.G1.Tau, _phase1.Parameters.G2.Tau, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case *groth16_bw6633.Phase1:
+		srs, err := plonk_bw6633.NewSRS(_phase1.Parameters.G1.Tau, _phase1.Parameters.G2.Tau, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	default:
+		panic("unrecognized Phase1 curve type")
+	}
+}
+
+// ReadPtau returns the KZG SRS for the constraint system from a snarkjs .ptau file on the same curve
+func ReadPtau(r io.Reader, ccs frontend.CompiledConstraintSystem) (kzg.SRS, error) {
+	size := SRSSize(ccs)
+	switch ccs.CurveID() {
+	case ecc.BN254:
+		srs, err := plonk_bn254.ReadPtau(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BLS12_381:
+		srs, err := plonk_bls12381.ReadPtau(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BLS12_377:
+		srs, err := plonk_bls12377.ReadPtau(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BW6_761:
+		srs, err := plonk_bw6761.ReadPtau(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BLS24_315:
+		srs, err := plonk_bls24315.ReadPtau(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	case ecc.BW6_633:
+		srs, err := plonk_bw6633.ReadPtau(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return srs, nil
+	default:
+		panic("not implemented")
+	}
+}
+
+// UnsupportedCurveError is returned when a SRS can't be read for the curve of a
+// constraint system
+type UnsupportedCurveError struct {
+	Curve ecc.ID
+}
+
+func (e *UnsupportedCurveError) Error() string {
+	return fmt.Sprintf("no SRS can be read for curve %s", e.Curve)
+}
+
+// ReadPPoTResponse returns the KZG SRS for the constraint system from a response file of
+// the perpetual powers of tau ceremony, with 2ᵖᵒʷᵉʳ powers of τ in G2.
+//
+// Only BN254 is supported, the curve of the ceremony: the file is read in the response
+// format of bellman_ce. For any other curve, an *UnsupportedCurveError is returned.
+func ReadPPoTResponse(r io.Reader, power int, ccs frontend.CompiledConstraintSystem) (kzg.SRS, error) {
+	if ccs.CurveID() != ecc.BN254 {
+		return nil, &UnsupportedCurveError{Curve: ccs.CurveID()}
+	}
+	srs, err := plonk_bn254.ReadPPoTResponse(r, power, SRSSize(ccs))
+	if err != nil {
+		return nil, err
+	}
+	return srs, nil
+}
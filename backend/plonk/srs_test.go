@@ -0,0 +1,272 @@
+package plonk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls12381fp "github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	bls12381fr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bn254fp "github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+type srsCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *srsCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(c.Y, api.Add(api.Mul(c.X, c.X, c.X), c.X, 5))
+	return nil
+}
+
+func TestSRSFromPhase1(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.PLONK, &srsCircuit{})
+		assert.NoError(err)
+
+		// SRSSize ≤ 2ᵖᵒʷᵉʳ⁺¹
+		power := ecc.NextPowerOfTwo(uint64(SRSSize(ccs)))
+		phase1, err := groth16.InitPhase1(curve, bitLen(power)-2)
+		assert.NoError(err)
+		assert.NoError(phase1.Contribute())
+
+		srs, err := NewSRS(ccs, phase1)
+		assert.NoError(err)
+		assertProve(t, ccs, srs)
+	}
+}
+
+func TestReadPtau(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.PLONK, &srsCircuit{})
+		assert.NoError(err)
+
+		const power = 4
+		ptau := writePtau(t, curve, power, false)
+		srs, err := ReadPtau(bytes.NewReader(ptau), ccs)
+		assert.NoError(err)
+		assertProve(t, ccs, srs)
+
+		// a stream is read sequentially
+		_, err = ReadPtau(bytes.NewBuffer(ptau), ccs)
+		assert.NoError(err)
+
+		// points which are not powers of the same τ
+		_, err = ReadPtau(bytes.NewReader(writePtau(t, curve, power, true)), ccs)
+		assert.Error(err)
+	}
+
+	// the prime of the file must match the curve of the circuit
+	ccs, err := frontend.Compile(ecc.BLS12_381, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	_, err = ReadPtau(bytes.NewReader(writePtau(t, ecc.BN254, 4, false)), ccs)
+	assert.Error(err)
+}
+
+func TestReadPPoTResponse(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+
+	const power = 4
+	response := writePPoTResponse(t, power)
+	srs, err := ReadPPoTResponse(bytes.NewReader(response), power, ccs)
+	assert.NoError(err)
+	assertProve(t, ccs, srs)
+
+	_, err = ReadPPoTResponse(bytes.NewBuffer(response), power, ccs)
+	assert.NoError(err)
+
+	// wrong power: the G2 points are read at the wrong offset
+	_, err = ReadPPoTResponse(bytes.NewReader(response), power-1, ccs)
+	assert.Error(err)
+
+	// the ceremony is on BN254 only
+	ccs, err = frontend.Compile(ecc.BLS12_381, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	_, err = ReadPPoTResponse(bytes.NewReader(response), power, ccs)
+	var curveErr *UnsupportedCurveError
+	assert.True(errors.As(err, &curveErr))
+	assert.Equal(ecc.BLS12_381, curveErr.Curve)
+}
+
+func assertProve(t *testing.T, ccs frontend.CompiledConstraintSystem, srs kzg.SRS) {
+	assert := require.New(t)
+
+	pk, vk, err := Setup(ccs, srs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ccs.CurveID())
+	assert.NoError(err)
+	proof, err := Prove(ccs, pk, w)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+}
+
+// writePtau returns a snarkjs .ptau file with the header and the τ sections, for a
+// random τ. If wrong is set, [τ³]1 is replaced by [τ⁴]1.
+func writePtau(t *testing.T, curve ecc.ID, power int, wrong bool) []byte {
+	n := 1 << power
+	var (
+		n8      int
+		q       *big.Int
+		g1, g2  [][]byte
+		writeFp func(buf []byte, limbs []uint64)
+	)
+	writeFp = func(buf []byte, limbs []uint64) {
+		for i := 0; i < len(limbs); i++ {
+			binary.LittleEndian.PutUint64(buf[8*i:], limbs[i])
+		}
+	}
+	switch curve {
+	case ecc.BN254:
+		n8, q = bn254fp.Bytes, bn254fp.Modulus()
+		taus := bn254Powers(t, 2*n-1)
+		_, _, gen1, gen2 := bn254.Generators()
+		for _, p := range bn254.BatchScalarMultiplicationG1(&gen1, taus) {
+			b := make([]byte, 2*n8)
+			writeFp(b, p.X[:])
+			writeFp(b[n8:], p.Y[:])
+			g1 = append(g1, b)
+		}
+		for _, p := range bn254.BatchScalarMultiplicationG2(&gen2, taus[:n]) {
+			b := make([]byte, 4*n8)
+			writeFp(b, p.X.A0[:])
+			writeFp(b[n8:], p.X.A1[:])
+			writeFp(b[2*n8:], p.Y.A0[:])
+			writeFp(b[3*n8:], p.Y.A1[:])
+			g2 = append(g2, b)
+		}
+	case ecc.BLS12_381:
+		n8, q = bls12381fp.Bytes, bls12381fp.Modulus()
+		taus := make([]bls12381fr.Element, 2*n-1)
+		var tau bls12381fr.Element
+		_, err := tau.SetRandom()
+		require.NoError(t, err)
+		taus[0].SetOne()
+		for i := 1; i < len(taus); i++ {
+			taus[i].Mul(&taus[i-1], &tau)
+		}
+		for i := 0; i < len(taus); i++ {
+			taus[i].FromMont()
+		}
+		_, _, gen1, gen2 := bls12381.Generators()
+		for _, p := range bls12381.BatchScalarMultiplicationG1(&gen1, taus) {
+			b := make([]byte, 2*n8)
+			writeFp(b, p.X[:])
+			writeFp(b[n8:], p.Y[:])
+			g1 = append(g1, b)
+		}
+		for _, p := range bls12381.BatchScalarMultiplicationG2(&gen2, taus[:n]) {
+			b := make([]byte, 4*n8)
+			writeFp(b, p.X.A0[:])
+			writeFp(b[n8:], p.X.A1[:])
+			writeFp(b[2*n8:], p.Y.A0[:])
+			writeFp(b[3*n8:], p.Y.A1[:])
+			g2 = append(g2, b)
+		}
+	}
+	if wrong {
+		g1[3] = g1[4]
+	}
+
+	var buf bytes.Buffer
+	u32 := func(v uint32) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+	u64 := func(v uint64) { _ = binary.Write(&buf, binary.LittleEndian, v) }
+	buf.WriteString("ptau")
+	u32(1)
+	u32(3)
+
+	// header: n8 | q | power | ceremony power
+	u32(1)
+	u64(uint64(4 + n8 + 4 + 4))
+	u32(uint32(n8))
+	qBytes := make([]byte, n8)
+	q.FillBytes(qBytes)
+	for i := len(qBytes) - 1; i >= 0; i-- {
+		buf.WriteByte(qBytes[i])
+	}
+	u32(uint32(power))
+	u32(uint32(power))
+
+	for section, points := range [][][]byte{g1, g2} {
+		u32(uint32(section + 2))
+		u64(uint64(len(points) * len(points[0])))
+		for _, p := range points {
+			buf.Write(p)
+		}
+	}
+	return buf.Bytes()
+}
+
+// writePPoTResponse returns the beginning of a response file of the perpetual powers of
+// tau ceremony for a random τ: the hash of the challenge, [τⁱ]1 and [τⁱ]2
+func writePPoTResponse(t *testing.T, power int) []byte {
+	n := 1 << power
+	taus := bn254Powers(t, 2*n-1)
+	_, _, gen1, gen2 := bn254.Generators()
+
+	// bellman_ce compressed flags: bit 7 set if y is the largest root
+	toBellman := func(b []byte) []byte {
+		if b[0]>>6 == 0b11 {
+			b[0] &^= 0b01 << 6
+		} else {
+			b[0] &^= 0b10 << 6
+		}
+		return b
+	}
+
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 64))
+	for _, p := range bn254.BatchScalarMultiplicationG1(&gen1, taus) {
+		b := p.Bytes()
+		buf.Write(toBellman(b[:]))
+	}
+	for _, p := range bn254.BatchScalarMultiplicationG2(&gen2, taus[:n]) {
+		b := p.Bytes()
+		buf.Write(toBellman(b[:]))
+	}
+	return buf.Bytes()
+}
+
+// bn254Powers returns τⁱ for i < n and a random τ, in regular form
+func bn254Powers(t *testing.T, n int) []bn254fr.Element {
+	taus := make([]bn254fr.Element, n)
+	var tau bn254fr.Element
+	_, err := tau.SetRandom()
+	require.NoError(t, err)
+	taus[0].SetOne()
+	for i := 1; i < n; i++ {
+		taus[i].Mul(&taus[i-1], &tau)
+	}
+	for i := 0; i < n; i++ {
+		taus[i].FromMont()
+	}
+	return taus
+}
+
+func bitLen(n uint64) int {
+	res := 0
+	for ; n != 0; n >>= 1 {
+		res++
+	}
+	return res
+}
@@ -0,0 +1,37 @@
+package plonk
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNbTasks(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := kzg.NewSRS(ecc.NextPowerOfTwo(uint64(SRSSize(ccs))), big.NewInt(42))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs, srs, backend.WithSetupNbTasks(1))
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	for _, nbTasks := range []int{1, 2, 3} {
+		assert.NoError(ccs.IsSolved(w, backend.WithNbTasks(nbTasks)))
+		proof, err := Prove(ccs, pk, w, backend.WithNbTasks(nbTasks))
+		assert.NoError(err)
+		assert.NoError(Verify(proof, vk, publicWitness))
+	}
+
+	_, _, err = Setup(ccs, srs, backend.WithSetupNbTasks(-1))
+	assert.Error(err)
+}
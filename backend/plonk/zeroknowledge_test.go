@@ -0,0 +1,182 @@
+package plonk
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+
+	kzg_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	kzg_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/kzg"
+	kzg_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	kzg_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/kzg"
+	kzg_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/kzg"
+)
+
+// newSRS returns a KZG SRS of the given size for curve, with a fixed secret
+func newSRS(curve ecc.ID, size uint64) (kzg.SRS, error) {
+	alpha := big.NewInt(42)
+	switch curve {
+	case ecc.BN254:
+		return kzg_bn254.NewSRS(size, alpha)
+	case ecc.BLS12_381:
+		return kzg_bls12381.NewSRS(size, alpha)
+	case ecc.BLS12_377:
+		return kzg_bls12377.NewSRS(size, alpha)
+	case ecc.BW6_761:
+		return kzg_bw6761.NewSRS(size, alpha)
+	case ecc.BLS24_315:
+		return kzg_bls24315.NewSRS(size, alpha)
+	case ecc.BW6_633:
+		return kzg_bw6633.NewSRS(size, alpha)
+	default:
+		panic("not implemented")
+	}
+}
+
+func TestWithoutZeroKnowledge(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range ecc.Implemented() {
+		ccs, err := frontend.Compile(curve, backend.PLONK, &srsCircuit{})
+		assert.NoError(err)
+		w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, curve)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+
+		// the proofs are not blinded: the SRS needs 3 points less
+		srs, err := newSRS(curve, uint64(SRSSize(ccs)-3))
+		assert.NoError(err)
+		pk, vk, err := Setup(ccs, srs, backend.WithSetupWithoutZeroKnowledge())
+		assert.NoError(err)
+
+		proof, err := Prove(ccs, pk, w, backend.WithoutZeroKnowledge())
+		assert.NoError(err, curve.String())
+		assert.NoError(Verify(proof, vk, publicWitness), curve.String())
+
+		// without blinding, the prover is deterministic
+		other, err := Prove(ccs, pk, w, backend.WithoutZeroKnowledge())
+		assert.NoError(err)
+		var b1, b2 bytes.Buffer
+		_, err = proof.WriteTo(&b1)
+		assert.NoError(err)
+		_, err = other.WriteTo(&b2)
+		assert.NoError(err)
+		assert.Equal(b1.Bytes(), b2.Bytes(), curve.String())
+
+		// the flag is part of the verifying key
+		var vkBuf bytes.Buffer
+		_, err = vk.WriteTo(&vkBuf)
+		assert.NoError(err)
+		vkRead := NewVerifyingKey(curve)
+		_, err = vkRead.ReadFrom(&vkBuf)
+		assert.NoError(err)
+		assert.NoError(vkRead.InitKZG(srs))
+		assert.NoError(Verify(proof, vkRead, publicWitness), curve.String())
+
+		// the prover option must match the proving key
+		_, err = Prove(ccs, pk, w)
+		assert.Error(err)
+		zkSRS, err := newSRS(curve, uint64(SRSSize(ccs)))
+		assert.NoError(err)
+		zkPK, zkVK, err := Setup(ccs, zkSRS)
+		assert.NoError(err)
+		_, err = Prove(ccs, zkPK, w, backend.WithoutZeroKnowledge())
+		assert.Error(err)
+
+		// a zero-knowledge verifying key rejects proofs that are not blinded
+		assert.Error(Verify(proof, zkVK, publicWitness), curve.String())
+	}
+}
+
+func TestWithoutZeroKnowledgeMappedAndCheckpoint(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	srs, err := newSRS(ecc.BN254, uint64(SRSSize(ccs)-3))
+	assert.NoError(err)
+	pk, vk, err := Setup(ccs, srs, backend.WithSetupWithoutZeroKnowledge())
+	assert.NoError(err)
+
+	// a mapped proving key needs as many SRS points as the unblinded polynomials
+	path := filepath.Join(t.TempDir(), "pk")
+	f, err := os.Create(path)
+	assert.NoError(err)
+	_, err = WriteMappedProvingKey(f, pk)
+	assert.NoError(err)
+	assert.NoError(f.Close())
+	mapped, closer, err := MapProvingKey(path, ecc.BN254)
+	assert.NoError(err)
+	defer closer.Close()
+	proof, err := Prove(ccs, mapped, w, backend.WithoutZeroKnowledge())
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	// a checkpoint holds polynomials of the unblinded sizes
+	path = filepath.Join(t.TempDir(), "proof.checkpoint")
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err = Prove(ccs, pk, w, backend.WithoutZeroKnowledge(), backend.WithCheckpoint(path), backend.WithContext(ctx), backend.WithObserver(cancelOnCheckpoint{cancel}))
+	assert.ErrorIs(err, context.Canceled)
+	f, err = os.Open(path)
+	assert.NoError(err)
+	defer f.Close()
+	proof, err = Resume(ccs, pk, w, f, backend.WithoutZeroKnowledge())
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+}
+
+// TestReadVersion1 reads keys written before the format version 2, in which the verifying keys
+// don't encode NoZeroKnowledge. They were generated by Setup(ccs, srs) for srsCircuit on BN254,
+// with the SRS of secret 42 of size ecc.NextPowerOfTwo(SRSSize(ccs)).
+func TestReadVersion1(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254, backend.PLONK, &srsCircuit{})
+	assert.NoError(err)
+	srs, err := newSRS(ecc.BN254, ecc.NextPowerOfTwo(uint64(SRSSize(ccs))))
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&srsCircuit{X: 3, Y: 35}, ecc.BN254)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+
+	f, err := os.Open("testdata/v1_bn254.vk")
+	assert.NoError(err)
+	defer f.Close()
+	vk, _, err := ReadVerifyingKey(f, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(vk.InitKZG(srs))
+
+	f, err = os.Open("testdata/v1_bn254.pk")
+	assert.NoError(err)
+	defer f.Close()
+	pk, _, err := ReadProvingKey(f, ecc.BN254)
+	assert.NoError(err)
+	assert.NoError(pk.InitKZG(srs))
+
+	proof, err := Prove(ccs, pk, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+
+	mapped, closer, err := MapProvingKey("testdata/v1_bn254.mapped.pk", ecc.BN254)
+	assert.NoError(err)
+	defer closer.Close()
+	proof, err = Prove(ccs, mapped, w)
+	assert.NoError(err)
+	assert.NoError(Verify(proof, vk, publicWitness))
+}
@@ -42,15 +42,18 @@ package witness
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"reflect"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/debug"
 	"github.com/consensys/gnark/frontend/schema"
+	gnarkio "github.com/consensys/gnark/io"
 )
 
 var (
@@ -142,6 +145,36 @@ func (w *Witness) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// WriteTo implements io.WriterTo. The binary encoding of the witness (see MarshalBinary)
+// is written in a container (see gnark/io) recording the CurveID.
+func (w *Witness) WriteTo(wr io.Writer) (int64, error) {
+	if w.Vector == nil {
+		return 0, fmt.Errorf("%w: empty witness", ErrInvalidWitness)
+	}
+	return gnarkio.WriteContainer(wr, gnarkio.Header{Kind: gnarkio.KindWitness, Curve: w.CurveID}, w.Vector)
+}
+
+// ReadFrom implements io.ReaderFrom, and reads a witness written by WriteTo.
+// If w.CurveID is set, a witness for another curve is rejected with a *gnarkio.MismatchError;
+// otherwise it is set from the container.
+func (w *Witness) ReadFrom(r io.Reader) (int64, error) {
+	expected := gnarkio.Header{Kind: gnarkio.KindWitness, Curve: w.CurveID}
+	var v Vector
+	h, n, err := gnarkio.ReadContainer(r, expected, func(h gnarkio.Header, r io.Reader) (int64, error) {
+		var err error
+		if v, err = newVector(h.Curve); err != nil {
+			return 0, err
+		}
+		return v.ReadFrom(r)
+	})
+	if err != nil {
+		return n, err
+	}
+	w.CurveID = h.Curve
+	w.Vector = v
+	return n, nil
+}
+
 // MarshalJSON implements json.Marshaler
 //
 // Only the vector of field elements is marshalled: the curveID and the Schema are omitted.
@@ -208,6 +241,69 @@ func (w *Witness) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalSnarkJS encodes the public part of the witness as snarkjs's public.json,
+// a list of decimal strings.
+//
+// If the witness is a full witness, the Schema must be set to extract the public part.
+func (w *Witness) MarshalSnarkJS() ([]byte, error) {
+	data, err := w.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// binary encoding is [uint32(len) | elements (big endian)]
+	n := int(binary.BigEndian.Uint32(data[:4]))
+	if w.Schema != nil {
+		if n == w.Schema.NbPublic+w.Schema.NbSecret {
+			n = w.Schema.NbPublic
+		} else if n != w.Schema.NbPublic {
+			return nil, fmt.Errorf("%w: got %d elements, expected either %d (public) or %d (full)", ErrInvalidWitness, n, w.Schema.NbPublic, w.Schema.NbPublic+w.Schema.NbSecret)
+		}
+	}
+	frSize := w.CurveID.Info().Fr.Bytes
+	if len(data) < 4+n*frSize {
+		return nil, fmt.Errorf("%w: unexpected binary encoding", ErrInvalidWitness)
+	}
+
+	values := make([]string, n)
+	var b big.Int
+	for i := 0; i < n; i++ {
+		b.SetBytes(data[4+i*frSize : 4+(i+1)*frSize])
+		values[i] = b.String()
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalSnarkJS decodes a public witness encoded as snarkjs's public.json.
+//
+// The CurveID must be set; if the Schema is set, the number of elements is checked.
+func (w *Witness) UnmarshalSnarkJS(data []byte) error {
+	if w.CurveID == ecc.UNKNOWN {
+		return errMissingCurveID
+	}
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if w.Schema != nil && len(values) != w.Schema.NbPublic {
+		return fmt.Errorf("%w: got %d elements, expected %d (public)", ErrInvalidWitness, len(values), w.Schema.NbPublic)
+	}
+
+	modulus := w.CurveID.Info().Fr.Modulus()
+	frSize := w.CurveID.Info().Fr.Bytes
+	buf := make([]byte, 4+len(values)*frSize)
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(values)))
+	var b big.Int
+	for i := 0; i < len(values); i++ {
+		if _, ok := b.SetString(values[i], 10); !ok || b.Sign() < 0 || b.Cmp(modulus) >= 0 {
+			return fmt.Errorf("%w: %q is not a field element", ErrInvalidWitness, values[i])
+		}
+		b.FillBytes(buf[4+i*frSize : 4+(i+1)*frSize])
+	}
+
+	return w.UnmarshalBinary(buf)
+}
+
 func (w *Witness) toAssignment(to interface{}, toLeafType reflect.Type) error {
 	if w.Schema == nil {
 		return errMissingSchema
@@ -32,7 +32,8 @@ func main() {
 
 	// gnark objects implements binary encoding using (or not) elliptic curve point compression
 	// groth16.ProvingKey, groth16.VerifyingKey and groth16.Proof implements io.WriterTo and io.ReaderFrom
-	// but also gnarkio.WriterRawTo to serialize without point compression
+	// but also gnarkio.WriterRawTo to serialize without point compression; both are written in
+	// a container (see gnarkio.WriteContainer) recording the curve and the format version
 	buf.Reset()
 	_, _ = pk.WriteRawTo(&buf)
 	newPK := groth16.NewProvingKey(ecc.BN254)
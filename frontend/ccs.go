@@ -48,4 +48,10 @@ type CompiledConstraintSystem interface {
 
 	// GetConstraints return a human readable representation of the constraints
 	GetConstraints() [][]string
+
+	// WriteCircomR1CS encodes the constraint system in circom's .r1cs binary format
+	// and WriteCircomSymbols writes the matching .sym file, naming the wires after the
+	// circuit schema. Only R1CS are supported.
+	WriteCircomR1CS(w io.Writer) (int64, error)
+	WriteCircomSymbols(w io.Writer) error
 }
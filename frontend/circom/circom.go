@@ -0,0 +1,229 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package circom loads constraint systems and witnesses produced by circom
+// (.r1cs and .wtns binary files), such that they can be used with gnark's
+// Groth16 and PlonK backends.
+//
+// circom's internal signals are not recomputed by gnark's solver: they are
+// loaded as secret inputs of the constraint system, and their values are read
+// from the .wtns file along with the inputs.
+package circom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/schema"
+	bls12377r1cs "github.com/consensys/gnark/internal/backend/bls12-377/cs"
+	bls12381r1cs "github.com/consensys/gnark/internal/backend/bls12-381/cs"
+	bls24315r1cs "github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	bn254r1cs "github.com/consensys/gnark/internal/backend/bn254/cs"
+	bw6633r1cs "github.com/consensys/gnark/internal/backend/bw6-633/cs"
+	bw6761r1cs "github.com/consensys/gnark/internal/backend/bw6-761/cs"
+	"github.com/consensys/gnark/internal/backend/circom"
+	"github.com/consensys/gnark/internal/backend/compiled"
+)
+
+// ErrUnsupportedField is returned when the prime of a circom file is not the
+// scalar field of a curve supported by gnark
+var ErrUnsupportedField = errors.New("circom: prime doesn't match any supported curve")
+
+// newR1CS maps the supported curves to the constructor of their R1CS
+var newR1CS = map[ecc.ID]func(compiled.R1CS, []big.Int) frontend.CompiledConstraintSystem{
+	ecc.BN254: func(r compiled.R1CS, c []big.Int) frontend.CompiledConstraintSystem {
+		return bn254r1cs.NewR1CS(r, c)
+	},
+	ecc.BLS12_381: func(r compiled.R1CS, c []big.Int) frontend.CompiledConstraintSystem {
+		return bls12381r1cs.NewR1CS(r, c)
+	},
+	ecc.BLS12_377: func(r compiled.R1CS, c []big.Int) frontend.CompiledConstraintSystem {
+		return bls12377r1cs.NewR1CS(r, c)
+	},
+	ecc.BW6_761: func(r compiled.R1CS, c []big.Int) frontend.CompiledConstraintSystem {
+		return bw6761r1cs.NewR1CS(r, c)
+	},
+	ecc.BLS24_315: func(r compiled.R1CS, c []big.Int) frontend.CompiledConstraintSystem {
+		return bls24315r1cs.NewR1CS(r, c)
+	},
+	ecc.BW6_633: func(r compiled.R1CS, c []big.Int) frontend.CompiledConstraintSystem {
+		return bw6633r1cs.NewR1CS(r, c)
+	},
+}
+
+// ReadR1CS reads a constraint system in circom's .r1cs binary format.
+//
+// The curve is deduced from the prime of the file (bn128 / BN254 or BLS12-381
+// with circom's default options). Public outputs and public inputs become public
+// inputs, in this order; private inputs and internal signals become secret
+// inputs, in this order.
+func ReadR1CS(r io.Reader) (frontend.CompiledConstraintSystem, error) {
+	header, constraints, _, err := circom.ReadR1CS(r)
+	if err != nil {
+		return nil, err
+	}
+	curveID, err := curveFromPrime(&header.Prime)
+	if err != nil {
+		return nil, err
+	}
+
+	nbPublic := 1 + int(header.NbPubOut) + int(header.NbPubIn)
+	nbSecret := int(header.NbWires) - nbPublic
+
+	res := compiled.R1CS{
+		CS: compiled.CS{
+			NbPublicVariables: nbPublic,
+			NbSecretVariables: nbSecret,
+			MDebug:            make(map[int]int),
+			MHints:            make(map[int]*compiled.Hint),
+			Schema:            newSchema(nbPublic-1, nbSecret),
+		},
+		Constraints: make([]compiled.R1C, len(constraints)),
+	}
+
+	// coefficients table, starting with the constants expected by the backends
+	coeffs := make([]big.Int, 4)
+	coeffs[compiled.CoeffIdZero].SetInt64(0)
+	coeffs[compiled.CoeffIdOne].SetInt64(1)
+	coeffs[compiled.CoeffIdTwo].SetInt64(2)
+	coeffs[compiled.CoeffIdMinusOne].SetInt64(-1)
+	coeffIDs := make(map[string]int)
+	coeffIDs["0"] = compiled.CoeffIdZero
+	coeffIDs["1"] = compiled.CoeffIdOne
+	coeffIDs["2"] = compiled.CoeffIdTwo
+	coeffIDs[new(big.Int).Sub(&header.Prime, big.NewInt(1)).String()] = compiled.CoeffIdMinusOne
+
+	toLinearExpression := func(l circom.LinearCombination) compiled.LinearExpression {
+		r := make(compiled.LinearExpression, len(l))
+		for i := 0; i < len(l); i++ {
+			key := l[i].Coeff.String()
+			cID, ok := coeffIDs[key]
+			if !ok {
+				cID = len(coeffs)
+				coeffs = append(coeffs, l[i].Coeff)
+				coeffIDs[key] = cID
+			}
+			visibility := schema.Secret
+			if int(l[i].Wire) < nbPublic {
+				visibility = schema.Public
+			}
+			r[i] = compiled.Pack(int(l[i].Wire), cID, visibility)
+		}
+		return r
+	}
+
+	for i := 0; i < len(constraints); i++ {
+		res.Constraints[i].L.LinExp = toLinearExpression(constraints[i][0])
+		res.Constraints[i].R.LinExp = toLinearExpression(constraints[i][1])
+		res.Constraints[i].O.LinExp = toLinearExpression(constraints[i][2])
+	}
+
+	// all the wires are inputs, the constraints can be checked in any order
+	if len(res.Constraints) != 0 {
+		level := make([]int, len(res.Constraints))
+		for i := 0; i < len(level); i++ {
+			level[i] = i
+		}
+		res.Levels = [][]int{level}
+	}
+
+	return newR1CS[curveID](res, coeffs), nil
+}
+
+// ReadWitness reads a witness in circom's .wtns binary format, for the constraint
+// system returned by ReadR1CS. The public part is obtained with witness.Public().
+func ReadWitness(r io.Reader, ccs frontend.CompiledConstraintSystem) (*witness.Witness, error) {
+	_, prime, values, err := circom.ReadWitness(r)
+	if err != nil {
+		return nil, err
+	}
+	curveID, err := curveFromPrime(&prime)
+	if err != nil {
+		return nil, err
+	}
+	if curveID != ccs.CurveID() {
+		return nil, fmt.Errorf("circom: witness is defined on %s, constraint system on %s", curveID, ccs.CurveID())
+	}
+	internal, secret, public := ccs.GetNbVariables()
+	if internal != 0 || len(values) != public+secret {
+		return nil, fmt.Errorf("%w: got %d wires, expected %d", witness.ErrInvalidWitness, len(values), public+secret+internal)
+	}
+	if values[0].Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("%w: first wire must be one", witness.ErrInvalidWitness)
+	}
+
+	// binary encoding of the witness: [uint32(len) | elements (big endian)]
+	frSize := curveID.Info().Fr.Bytes
+	var buf bytes.Buffer
+	buf.Grow(4 + (len(values)-1)*frSize)
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(len(values)-1))
+	buf.Write(b[:])
+	e := make([]byte, frSize)
+	for i := 1; i < len(values); i++ {
+		values[i].FillBytes(e)
+		buf.Write(e)
+	}
+
+	res, err := witness.New(curveID, ccs.GetSchema())
+	if err != nil {
+		return nil, err
+	}
+	if err := res.UnmarshalBinary(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// curveFromPrime returns the supported curve whose scalar field modulus is prime
+func curveFromPrime(prime *big.Int) (ecc.ID, error) {
+	for id := range newR1CS {
+		if id.Info().Fr.Modulus().Cmp(prime) == 0 {
+			return id, nil
+		}
+	}
+	return ecc.UNKNOWN, ErrUnsupportedField
+}
+
+// newSchema returns a schema with two arrays, Public and Secret, matching the
+// wires of the constraint system
+func newSchema(nbPublic, nbSecret int) *schema.Schema {
+	s := &schema.Schema{NbPublic: nbPublic, NbSecret: nbSecret}
+	if nbPublic != 0 {
+		s.Fields = append(s.Fields, schema.Field{
+			Name:       "Public",
+			Type:       schema.Array,
+			Visibility: schema.Public,
+			ArraySize:  nbPublic,
+		})
+	}
+	if nbSecret != 0 {
+		s.Fields = append(s.Fields, schema.Field{
+			Name:       "Secret",
+			Type:       schema.Array,
+			Visibility: schema.Secret,
+			ArraySize:  nbSecret,
+		})
+	}
+	return s
+}
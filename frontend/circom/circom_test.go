@@ -0,0 +1,124 @@
+package circom
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/stretchr/testify/require"
+)
+
+// the fixtures encode, in circom's format:
+//
+//	signal input x;
+//	signal input y; // public
+//	signal output out;
+//	signal tmp;
+//	tmp <== x*x;
+//	out <== tmp*x + y;
+//
+// with x = 3, y = 5 (out = 32).
+var fixtures = map[string]ecc.ID{
+	"example_bn254":    ecc.BN254,
+	"example_bls12381": ecc.BLS12_381,
+}
+
+func TestReadR1CS(t *testing.T) {
+	assert := require.New(t)
+
+	for name, curve := range fixtures {
+		ccs, w := readFixture(t, name)
+		assert.Equal(curve, ccs.CurveID())
+
+		internal, secret, public := ccs.GetNbVariables()
+		assert.Equal(0, internal)
+		assert.Equal(2, secret, "x and tmp")
+		assert.Equal(3, public, "one, out and y")
+		assert.Equal(2, ccs.GetNbConstraints())
+
+		assert.NoError(ccs.IsSolved(w))
+
+		// groth16
+		pk, vk, err := groth16.Setup(ccs)
+		assert.NoError(err)
+		proof, err := groth16.Prove(ccs, pk, w)
+		assert.NoError(err)
+		publicWitness, err := w.Public()
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+		// wrong witness
+		data, err := os.ReadFile("testdata/" + name + ".wtns")
+		assert.NoError(err)
+		data[len(data)-1] ^= 1 // tmp
+		bad, err := ReadWitness(bytes.NewReader(data), ccs)
+		assert.NoError(err)
+		assert.Error(ccs.IsSolved(bad))
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	for name := range fixtures {
+		ccs, w := readFixture(t, name)
+
+		var buf bytes.Buffer
+		_, err := ccs.WriteCircomR1CS(&buf)
+		assert.NoError(err)
+
+		reconstructed, err := ReadR1CS(&buf)
+		assert.NoError(err)
+		assert.Equal(ccs.CurveID(), reconstructed.CurveID())
+		assert.Equal(ccs.GetNbConstraints(), reconstructed.GetNbConstraints())
+		i1, s1, p1 := ccs.GetNbVariables()
+		i2, s2, p2 := reconstructed.GetNbVariables()
+		assert.Equal([]int{i1, s1, p1}, []int{i2, s2, p2})
+		assert.Equal(ccs.GetConstraints(), reconstructed.GetConstraints())
+		assert.NoError(reconstructed.IsSolved(w))
+	}
+}
+
+func TestWrongCurve(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, _ := readFixture(t, "example_bn254")
+	f, err := os.Open("testdata/example_bls12381.wtns")
+	assert.NoError(err)
+	defer f.Close()
+	_, err = ReadWitness(f, ccs)
+	assert.Error(err)
+}
+
+func TestUnsupportedField(t *testing.T) {
+	assert := require.New(t)
+
+	data, err := os.ReadFile("testdata/example_bn254.r1cs")
+	assert.NoError(err)
+	data[28+31] |= 0x80 // the prime (little-endian, in the header section) is now larger
+	_, err = ReadR1CS(bytes.NewReader(data))
+	assert.ErrorIs(err, ErrUnsupportedField)
+}
+
+func readFixture(t *testing.T, name string) (ccs frontend.CompiledConstraintSystem, w *witness.Witness) {
+	t.Helper()
+	assert := require.New(t)
+
+	f, err := os.Open("testdata/" + name + ".r1cs")
+	assert.NoError(err)
+	defer f.Close()
+	ccs, err = ReadR1CS(f)
+	assert.NoError(err)
+
+	fw, err := os.Open("testdata/" + name + ".wtns")
+	assert.NoError(err)
+	defer fw.Close()
+	w, err = ReadWitness(fw, ccs)
+	assert.NoError(err)
+
+	return ccs, w
+}
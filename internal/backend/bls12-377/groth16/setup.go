@@ -23,9 +23,14 @@ import (
 
 	"github.com/consensys/gnark/internal/backend/bls12-377/cs"
 
+	"context"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/backend/compiled"
+	"github.com/consensys/gnark/internal/utils"
+	gnarkio "github.com/consensys/gnark/io"
+	"io"
 	"math/big"
 	"math/bits"
 )
@@ -53,6 +58,9 @@ type ProvingKey struct {
 	// if InfinityA[i] == true, the point G1.A[i] == infinity
 	InfinityA, InfinityB     []bool
 	NbInfinityA, NbInfinityB uint64
+
+	// fixed-base tables of the points above, see Precompute
+	precomputed fixedBaseTables // not serialized with the key
 }
 
 // VerifyingKey is used by a Groth16 verifier to verify the validity of a proof and a statement
@@ -78,37 +86,34 @@ type VerifyingKey struct {
 
 // Setup constructs the SRS
 func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
+	return SetupContext(context.Background(), r1cs, pk, vk, backend.SetupConfig{})
+}
 
-	/*
-		Setup
-		-----
-		To build the verifying keys:
-		- compile the r1cs system -> the number of gates is len(GateOrdering)+len(PureStructuralConstraints)+len(InpureStructuralConstraints)
-		- loop through the ordered computational constraints (=gate in r1cs system structure), eValuate A(X), B(X), C(X) with simple formula (the gate number is the current iterator)
-		- loop through the inpure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+ current iterator
-		- loop through the pure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+len(InpureStructuralConstraints)+current iterator
-	*/
-
-	// get R1CS nb constraints, wires and public/private inputs
-	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
-	nbPublicWires := int(r1cs.NbPublicVariables)
-	nbPrivateWires := r1cs.NbSecretVariables + r1cs.NbInternalVariables
+// SetupContext behaves like Setup, and returns ctx.Err() if ctx is done before the
+// scalar multiplications. The scalar multiplications run in at most opt.NbTasks tasks.
+func SetupContext(ctx context.Context, r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey, opt backend.SetupConfig) error {
 
 	// Setting group for fft
 	domain := fft.NewDomain(uint64(len(r1cs.Constraints)))
 
-	// samples toxic waste
-	toxicWaste, err := sampleToxicWaste()
+	scalars, err := newSetupScalars(r1cs, domain)
 	if err != nil {
 		return err
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	toxicWaste := scalars.toxicWaste
+	A, B, pkK, Z, vkK := scalars.A, scalars.B, scalars.pkK, scalars.Z, scalars.vkK
+	nbWires := len(scalars.infinityA)
+	nbPrivateWires := len(pkK)
 
-	// Setup coeffs to compute pk.G1.A, pk.G1.B, pk.G1.K
-	A, B, C := setupABC(r1cs, domain, toxicWaste)
+	pk.InfinityA, pk.InfinityB = scalars.infinityA, scalars.infinityB
+	pk.NbInfinityA, pk.NbInfinityB = scalars.nbInfinityA, scalars.nbInfinityB
 
 	// To fill in the Proving and Verifying keys, we need to perform a lot of ecc scalar multiplication (with generator)
 	// and convert the resulting points to affine
-	// this is done using the curve.BatchScalarMultiplicationGX API, which takes as input the base point
+	// this is done using the batchScalarMultiplicationGX functions, which takes as input the base point
 	// (in our case the generator) and the list of scalars, and outputs a list of points (len(points) == len(scalars))
 	// to use this batch call, we need to order our scalars in the same slice
 	// we have 1 batch call for G1 and 1 batch call for G1
@@ -126,79 +131,6 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// len(vk.K) == nbPublicWires
 	// len(Z) == domain.Cardinality
 
-	// compute scalars for pkK and vkK
-	pkK := make([]fr.Element, nbPrivateWires)
-	vkK := make([]fr.Element, nbPublicWires)
-
-	var t0, t1 fr.Element
-
-	for i := 0; i < nbPublicWires; i++ {
-		t1.Mul(&A[i], &toxicWaste.beta)
-		t0.Mul(&B[i], &toxicWaste.alpha)
-		t1.Add(&t1, &t0).
-			Add(&t1, &C[i]).
-			Mul(&t1, &toxicWaste.gammaInv)
-		vkK[i] = t1.ToRegular()
-	}
-
-	for i := 0; i < nbPrivateWires; i++ {
-		t1.Mul(&A[i+nbPublicWires], &toxicWaste.beta)
-		t0.Mul(&B[i+nbPublicWires], &toxicWaste.alpha)
-		t1.Add(&t1, &t0).
-			Add(&t1, &C[i+nbPublicWires]).
-			Mul(&t1, &toxicWaste.deltaInv)
-		pkK[i] = t1.ToRegular()
-	}
-
-	// convert A and B to regular form
-	for i := 0; i < int(nbWires); i++ {
-		A[i].FromMont()
-	}
-	for i := 0; i < int(nbWires); i++ {
-		B[i].FromMont()
-	}
-
-	// Z part of the proving key (scalars)
-	Z := make([]fr.Element, domain.Cardinality)
-	one := fr.One()
-	var zdt fr.Element
-
-	zdt.Exp(toxicWaste.t, new(big.Int).SetUint64(domain.Cardinality)).
-		Sub(&zdt, &one).
-		Mul(&zdt, &toxicWaste.deltaInv) // sets Zdt to Zdt/delta
-
-	for i := 0; i < int(domain.Cardinality); i++ {
-		Z[i] = zdt.ToRegular()
-		zdt.Mul(&zdt, &toxicWaste.t)
-	}
-
-	// mark points at infinity and filter them
-	pk.InfinityA = make([]bool, len(A))
-	pk.InfinityB = make([]bool, len(B))
-
-	n := 0
-	for i, e := range A {
-		if e.IsZero() {
-			pk.InfinityA[i] = true
-			continue
-		}
-		A[n] = A[i]
-		n++
-	}
-	A = A[:n]
-	pk.NbInfinityA = uint64(nbWires - n)
-	n = 0
-	for i, e := range B {
-		if e.IsZero() {
-			pk.InfinityB[i] = true
-			continue
-		}
-		B[n] = B[i]
-		n++
-	}
-	B = B[:n]
-	pk.NbInfinityB = uint64(nbWires - n)
-
 	// compute our batch scalar multiplication with g1 elements
 	g1Scalars := make([]fr.Element, 0, (nbWires*3)+int(domain.Cardinality)+3)
 	g1Scalars = append(g1Scalars, toxicWaste.alphaReg, toxicWaste.betaReg, toxicWaste.deltaReg)
@@ -208,7 +140,10 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	g1Scalars = append(g1Scalars, Z...)
 	g1Scalars = append(g1Scalars, vkK...)
 
-	g1PointsAff := curve.BatchScalarMultiplicationG1(&g1, g1Scalars)
+	g1PointsAff := batchScalarMultiplicationG1(&g1, g1Scalars, opt.NbTasks)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// sets pk: [α]1, [β]1, [δ]1
 	pk.G1.Alpha = g1PointsAff[0]
@@ -225,8 +160,8 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	pk.G1.K = g1PointsAff[offset : offset+nbPrivateWires]
 	offset += nbPrivateWires
 
+	// Z scalars are already in bit-reversed order
 	pk.G1.Z = g1PointsAff[offset : offset+int(domain.Cardinality)]
-	bitReverse(pk.G1.Z)
 
 	offset += int(domain.Cardinality)
 
@@ -243,7 +178,7 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	// compute our batch scalar multiplication with g2 elements
 	g2Scalars := append(B, toxicWaste.betaReg, toxicWaste.deltaReg, toxicWaste.gammaReg)
 
-	g2PointsAff := curve.BatchScalarMultiplicationG2(&g2, g2Scalars)
+	g2PointsAff := batchScalarMultiplicationG2(&g2, g2Scalars, opt.NbTasks)
 
 	pk.G2.B = g2PointsAff[:len(B)]
 
@@ -276,6 +211,303 @@ func Setup(r1cs *cs.R1CS, pk *ProvingKey, vk *VerifyingKey) error {
 	return nil
 }
 
+// setupChunkSize is the maximum number of points SetupTo holds in memory at once
+const setupChunkSize = 1 << 16
+
+// SetupTo behaves like SetupContext, but instead of building the keys in memory it writes the
+// ProvingKey to pkw and the VerifyingKey to vkw, as their WriteRawTo methods do.
+//
+// The points of the proving key are computed and written by chunks of setupChunkSize, so that
+// only the scalars they derive from are fully held in memory.
+func SetupTo(ctx context.Context, r1cs *cs.R1CS, pkw, vkw io.Writer, opt backend.SetupConfig) error {
+
+	// Setting group for fft
+	domain := fft.NewDomain(uint64(len(r1cs.Constraints)))
+
+	scalars, err := newSetupScalars(r1cs, domain)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	toxicWaste := scalars.toxicWaste
+
+	_, _, g1, g2 := curve.Generators()
+
+	// [α]1, [β]1, [δ]1 and [β]2, [δ]2, [γ]2
+	g1PointsAff := batchScalarMultiplicationG1(&g1, []fr.Element{toxicWaste.alphaReg, toxicWaste.betaReg, toxicWaste.deltaReg}, opt.NbTasks)
+	g2PointsAff := batchScalarMultiplicationG2(&g2, []fr.Element{toxicWaste.betaReg, toxicWaste.deltaReg, toxicWaste.gammaReg}, opt.NbTasks)
+
+	// the verifying key is small, we build it in memory
+	var vk VerifyingKey
+	vk.G1.Alpha = g1PointsAff[0]
+	vk.G1.Beta = g1PointsAff[1]
+	vk.G1.Delta = g1PointsAff[2]
+	vk.G1.K = batchScalarMultiplicationG1(&g1, scalars.vkK, opt.NbTasks)
+	vk.G2.Beta = g2PointsAff[0]
+	vk.G2.Delta = g2PointsAff[1]
+	vk.G2.Gamma = g2PointsAff[2]
+	if _, err := vk.WriteRawTo(vkw); err != nil {
+		return err
+	}
+
+	// the proving key is written in a container, section by section in the order of
+	// ProvingKey.writeTo
+	_, err = gnarkio.WriteContainer(pkw, header(gnarkio.KindProvingKey), gnarkio.WriterToFunc(func(w io.Writer) (int64, error) {
+		n, err := domain.WriteTo(w)
+		if err != nil {
+			return n, err
+		}
+		enc := curve.NewEncoder(w, curve.RawEncoding())
+		err = encodeProvingKeyPoints(ctx, enc, &scalars, g1PointsAff, g2PointsAff, opt.NbTasks)
+		return n + enc.BytesWritten(), err
+	}))
+	return err
+}
+
+// encodeProvingKeyPoints encodes the sections of the proving key following its domain, from
+// [α]1, [β]1, [δ]1, [β]2 and [δ]2, and the scalars of the other points
+func encodeProvingKeyPoints(ctx context.Context, enc *curve.Encoder, scalars *setupScalars, g1PointsAff []curve.G1Affine, g2PointsAff []curve.G2Affine, nbTasks int) error {
+	_, _, g1, g2 := curve.Generators()
+
+	for _, p := range []*curve.G1Affine{&g1PointsAff[0], &g1PointsAff[1], &g1PointsAff[2]} {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	for _, s := range [][]fr.Element{scalars.A, scalars.B, scalars.Z, scalars.pkK} {
+		if err := encodeG1Chunked(ctx, enc, &g1, s, nbTasks); err != nil {
+			return err
+		}
+	}
+	for _, p := range []*curve.G2Affine{&g2PointsAff[0], &g2PointsAff[1]} {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	if err := encodeG2Chunked(ctx, enc, &g2, scalars.B, nbTasks); err != nil {
+		return err
+	}
+
+	toEncode := []interface{}{
+		uint64(len(scalars.infinityA)),
+		scalars.nbInfinityA,
+		scalars.nbInfinityB,
+		scalars.infinityA,
+		scalars.infinityB,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeG1Chunked encodes the points [scalars[i]]1 as enc.Encode would encode the whole slice,
+// computing at most setupChunkSize points at a time
+func encodeG1Chunked(ctx context.Context, enc *curve.Encoder, g1 *curve.G1Affine, scalars []fr.Element, nbTasks int) error {
+	if err := enc.Encode(uint32(len(scalars))); err != nil {
+		return err
+	}
+	for start := 0; start < len(scalars); start += setupChunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := start + setupChunkSize
+		if end > len(scalars) {
+			end = len(scalars)
+		}
+		points := batchScalarMultiplicationG1(g1, scalars[start:end], nbTasks)
+		for i := range points {
+			if err := enc.Encode(&points[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// encodeG2Chunked encodes the points [scalars[i]]2 as enc.Encode would encode the whole slice,
+// computing at most setupChunkSize points at a time
+func encodeG2Chunked(ctx context.Context, enc *curve.Encoder, g2 *curve.G2Affine, scalars []fr.Element, nbTasks int) error {
+	if err := enc.Encode(uint32(len(scalars))); err != nil {
+		return err
+	}
+	for start := 0; start < len(scalars); start += setupChunkSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := start + setupChunkSize
+		if end > len(scalars) {
+			end = len(scalars)
+		}
+		points := batchScalarMultiplicationG2(g2, scalars[start:end], nbTasks)
+		for i := range points {
+			if err := enc.Encode(&points[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// batchScalarMultiplicationG1 returns the points [scalars[i]]base, the scalars being in regular
+// form, computed in at most nbTasks concurrent tasks. curve.BatchScalarMultiplicationG1, which
+// shares a table of multiples of base but uses runtime.NumCPU() tasks, is called if nbTasks
+// isn't set.
+func batchScalarMultiplicationG1(base *curve.G1Affine, scalars []fr.Element, nbTasks int) []curve.G1Affine {
+	if nbTasks <= 0 {
+		return curve.BatchScalarMultiplicationG1(base, scalars)
+	}
+	points := make([]curve.G1Affine, len(scalars))
+	utils.Parallelize(len(scalars), func(start, end int) {
+		var s big.Int
+		for i := start; i < end; i++ {
+			points[i].ScalarMultiplication(base, scalars[i].ToBigInt(&s))
+		}
+	}, nbTasks)
+	return points
+}
+
+// batchScalarMultiplicationG2 is batchScalarMultiplicationG1 in G2
+func batchScalarMultiplicationG2(base *curve.G2Affine, scalars []fr.Element, nbTasks int) []curve.G2Affine {
+	if nbTasks <= 0 {
+		return curve.BatchScalarMultiplicationG2(base, scalars)
+	}
+	points := make([]curve.G2Affine, len(scalars))
+	utils.Parallelize(len(scalars), func(start, end int) {
+		var s big.Int
+		for i := start; i < end; i++ {
+			points[i].ScalarMultiplication(base, scalars[i].ToBigInt(&s))
+		}
+	}, nbTasks)
+	return points
+}
+
+// setupScalars holds the scalars, in regular form, from which the setup derives the points
+// of the proving and verifying keys
+type setupScalars struct {
+	toxicWaste toxicWaste
+
+	// A and B are filtered from their zeroes, Z is in bit-reversed order
+	A, B, pkK, Z, vkK []fr.Element
+
+	infinityA, infinityB     []bool
+	nbInfinityA, nbInfinityB uint64
+}
+
+// newSetupScalars samples the toxic waste and computes the setupScalars of r1cs
+func newSetupScalars(r1cs *cs.R1CS, domain *fft.Domain) (setupScalars, error) {
+
+	/*
+		Setup
+		-----
+		To build the verifying keys:
+		- compile the r1cs system -> the number of gates is len(GateOrdering)+len(PureStructuralConstraints)+len(InpureStructuralConstraints)
+		- loop through the ordered computational constraints (=gate in r1cs system structure), eValuate A(X), B(X), C(X) with simple formula (the gate number is the current iterator)
+		- loop through the inpure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+ current iterator
+		- loop through the pure structural constraints, eValuate A(X), B(X), C(X) with simple formula, the gate number is len(gateOrdering)+len(InpureStructuralConstraints)+current iterator
+	*/
+
+	var res setupScalars
+
+	// get R1CS nb constraints, wires and public/private inputs
+	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
+	nbPublicWires := int(r1cs.NbPublicVariables)
+	nbPrivateWires := r1cs.NbSecretVariables + r1cs.NbInternalVariables
+
+	// samples toxic waste
+	toxicWaste, err := sampleToxicWaste()
+	if err != nil {
+		return res, err
+	}
+	res.toxicWaste = toxicWaste
+
+	// Setup coeffs to compute pk.G1.A, pk.G1.B, pk.G1.K
+	A, B, C := setupABC(r1cs, domain, toxicWaste)
+
+	// compute scalars for pkK and vkK
+	pkK := make([]fr.Element, nbPrivateWires)
+	vkK := make([]fr.Element, nbPublicWires)
+
+	var t0, t1 fr.Element
+
+	for i := 0; i < nbPublicWires; i++ {
+		t1.Mul(&A[i], &toxicWaste.beta)
+		t0.Mul(&B[i], &toxicWaste.alpha)
+		t1.Add(&t1, &t0).
+			Add(&t1, &C[i]).
+			Mul(&t1, &toxicWaste.gammaInv)
+		vkK[i] = t1.ToRegular()
+	}
+
+	for i := 0; i < nbPrivateWires; i++ {
+		t1.Mul(&A[i+nbPublicWires], &toxicWaste.beta)
+		t0.Mul(&B[i+nbPublicWires], &toxicWaste.alpha)
+		t1.Add(&t1, &t0).
+			Add(&t1, &C[i+nbPublicWires]).
+			Mul(&t1, &toxicWaste.deltaInv)
+		pkK[i] = t1.ToRegular()
+	}
+
+	// convert A and B to regular form
+	for i := 0; i < int(nbWires); i++ {
+		A[i].FromMont()
+	}
+	for i := 0; i < int(nbWires); i++ {
+		B[i].FromMont()
+	}
+
+	// Z part of the proving key (scalars)
+	Z := make([]fr.Element, domain.Cardinality)
+	one := fr.One()
+	var zdt fr.Element
+
+	zdt.Exp(toxicWaste.t, new(big.Int).SetUint64(domain.Cardinality)).
+		Sub(&zdt, &one).
+		Mul(&zdt, &toxicWaste.deltaInv) // sets Zdt to Zdt/delta
+
+	for i := 0; i < int(domain.Cardinality); i++ {
+		Z[i] = zdt.ToRegular()
+		zdt.Mul(&zdt, &toxicWaste.t)
+	}
+	fft.BitReverse(Z)
+
+	// mark points at infinity and filter them
+	res.infinityA = make([]bool, len(A))
+	res.infinityB = make([]bool, len(B))
+
+	n := 0
+	for i, e := range A {
+		if e.IsZero() {
+			res.infinityA[i] = true
+			continue
+		}
+		A[n] = A[i]
+		n++
+	}
+	A = A[:n]
+	res.nbInfinityA = uint64(nbWires - n)
+	n = 0
+	for i, e := range B {
+		if e.IsZero() {
+			res.infinityB[i] = true
+			continue
+		}
+		B[n] = B[i]
+		n++
+	}
+	B = B[:n]
+	res.nbInfinityB = uint64(nbWires - n)
+
+	res.A, res.B, res.pkK, res.Z, res.vkK = A, B, pkK, Z, vkK
+
+	return res, nil
+}
+
 func setupABC(r1cs *cs.R1CS, domain *fft.Domain, toxicWaste toxicWaste) (A []fr.Element, B []fr.Element, C []fr.Element) {
 
 	nbWires := r1cs.NbInternalVariables + r1cs.NbPublicVariables + r1cs.NbSecretVariables
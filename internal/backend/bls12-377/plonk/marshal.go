@@ -19,14 +19,62 @@ package plonk
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
 
-	"errors"
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+
+	"errors"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
 	"io"
+
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
 )
 
-// WriteTo writes binary encoding of Proof to w
+// The objects are written in a container (see gnark/io), with the bare encoding of
+// writeTo as payload. ReadFrom also reads the bare encoding written by the versions of
+// gnark predating containers, as version 0.
+
+// header returns the header of the container of an object of the given kind
+func header(kind gnarkio.Kind) gnarkio.Header {
+	return gnarkio.Header{Kind: kind, Backend: backend.PLONK, Curve: curve.ID}
+}
+
+// payload returns the bare encoding of an object by writeTo, as payload of its container
+func payload(writeTo func(w io.Writer, raw bool) (int64, error), raw bool) io.WriterTo {
+	return gnarkio.WriterToFunc(func(w io.Writer) (int64, error) {
+		return writeTo(w, raw)
+	})
+}
+
+// readContainer reads the container of an object of the given kind, or its bare
+// encoding, with readPayload
+func readContainer(r io.Reader, kind gnarkio.Kind, readPayload func(version uint16, r io.Reader) (int64, error)) (int64, error) {
+	_, n, err := gnarkio.ReadContainerOrLegacy(r, header(kind), func(h gnarkio.Header, r io.Reader) (int64, error) {
+		return readPayload(h.Version, r)
+	})
+	return n, err
+}
+
+// WriteTo writes binary encoding of Proof to w, in a container
+// points are compressed
+// use WriteRawTo(...) to encode the proof without point compression
 func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
-	enc := curve.NewEncoder(w)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProof), payload(proof.writeTo, false))
+}
+
+// WriteRawTo writes binary encoding of Proof to w, in a container
+// points are not compressed
+// use WriteTo(...) to encode the proof with point compression
+func (proof *Proof) WriteRawTo(w io.Writer) (int64, error) {
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProof), payload(proof.writeTo, true))
+}
+
+func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
 
 	toEncode := []interface{}{
 		&proof.LRO[0],
@@ -36,6 +84,10 @@ func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
 		&proof.H[0],
 		&proof.H[1],
 		&proof.H[2],
+		&proof.BatchedProof.H,
+		proof.BatchedProof.ClaimedValues,
+		&proof.ZShiftedOpening.H,
+		&proof.ZShiftedOpening.ClaimedValue,
 	}
 
 	for _, v := range toEncode {
@@ -44,18 +96,27 @@ func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
 		}
 	}
 
-	n, err := proof.BatchedProof.WriteTo(w)
-	if err != nil {
-		return n + enc.BytesWritten(), err
-	}
-	n2, err := proof.ZShiftedOpening.WriteTo(w)
-
-	return n + n2 + enc.BytesWritten(), err
+	return enc.BytesWritten(), nil
 }
 
 // ReadFrom reads binary representation of Proof from r
+// Proof must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
-	dec := curve.NewDecoder(r)
+	return readContainer(r, gnarkio.KindProof, func(_ uint16, r io.Reader) (int64, error) {
+		return proof.readFrom(r)
+	})
+}
+
+// UnsafeReadFrom has the same behavior as ReadFrom, except that it will not check that decode points
+// are on the curve and in the correct subgroup.
+func (proof *Proof) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return readContainer(r, gnarkio.KindProof, func(_ uint16, r io.Reader) (int64, error) {
+		return proof.readFrom(r, curve.NoSubgroupChecks())
+	})
+}
+
+func (proof *Proof) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	dec := curve.NewDecoder(r, decOptions...)
 	toDecode := []interface{}{
 		&proof.LRO[0],
 		&proof.LRO[1],
@@ -64,6 +125,10 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 		&proof.H[0],
 		&proof.H[1],
 		&proof.H[2],
+		&proof.BatchedProof.H,
+		&proof.BatchedProof.ClaimedValues,
+		&proof.ZShiftedOpening.H,
+		&proof.ZShiftedOpening.ClaimedValue,
 	}
 
 	for _, v := range toDecode {
@@ -72,30 +137,33 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
-	n, err := proof.BatchedProof.ReadFrom(r)
-	if err != nil {
-		return n + dec.BytesRead(), err
-	}
-	n2, err := proof.ZShiftedOpening.ReadFrom(r)
-	return n + n2 + dec.BytesRead(), err
+	return dec.BytesRead(), nil
 }
 
-// WriteTo writes binary encoding of ProvingKey to w
+// WriteTo writes binary encoding of ProvingKey to w, in a container
+// points are compressed
+// use WriteRawTo(...) to encode the key without point compression
 func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
-	// encode the verifying key
-	n, err = pk.Vk.WriteTo(w)
-	if err != nil {
-		return
-	}
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProvingKey), payload(pk.writeTo, false))
+}
+
+// WriteRawTo writes binary encoding of ProvingKey to w, in a container
+// points are not compressed
+// use WriteTo(...) to encode the key with point compression
+func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProvingKey), payload(pk.writeTo, true))
+}
 
+// writeTo encodes the verifying key last (since version 3), so that SetupTo can write each
+// polynomial as soon as it is committed to
+func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (n int64, err error) {
 	// fft domains
-	n2, err := pk.Domain[0].WriteTo(w)
+	n, err = pk.Domain[0].WriteTo(w)
 	if err != nil {
 		return
 	}
-	n += n2
 
-	n2, err = pk.Domain[1].WriteTo(w)
+	n2, err := pk.Domain[1].WriteTo(w)
 	if err != nil {
 		return
 	}
@@ -106,7 +174,12 @@ func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
 		return n, errors.New("invalid permutation size, expected 3*domain cardinality")
 	}
 
-	enc := curve.NewEncoder(w)
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
 	// note: type Polynomial, which is handled by default binary.Write(...) op and doesn't
 	// encode the size (nor does it convert from Montgomery to Regular form)
 	// so we explicitly transmit []fr.Element
@@ -128,16 +201,36 @@ func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
 			return n + enc.BytesWritten(), err
 		}
 	}
+	n += enc.BytesWritten()
 
-	return n + enc.BytesWritten(), nil
+	// encode the verifying key
+	n2, err = pk.Vk.writeTo(w, raw)
+	return n + n2, err
 }
 
 // ReadFrom reads from binary representation in r into ProvingKey
+// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	return readContainer(r, gnarkio.KindProvingKey, func(version uint16, r io.Reader) (int64, error) {
+		return pk.readFrom(r, version)
+	})
+}
+
+// UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded points are on the curve
+// or in the correct subgroup
+func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return readContainer(r, gnarkio.KindProvingKey, func(version uint16, r io.Reader) (int64, error) {
+		return pk.readFrom(r, version, curve.NoSubgroupChecks())
+	})
+}
+
+func (pk *ProvingKey) readFrom(r io.Reader, version uint16, decOptions ...func(*curve.Decoder)) (n int64, err error) {
+	// before version 3, the verifying key was encoded first
 	pk.Vk = &VerifyingKey{}
-	n, err := pk.Vk.ReadFrom(r)
-	if err != nil {
-		return n, err
+	if version < 3 {
+		if n, err = pk.Vk.readFrom(r, version, decOptions...); err != nil {
+			return n, err
+		}
 	}
 
 	n2, err := pk.Domain[0].ReadFrom(r)
@@ -154,7 +247,7 @@ func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 
 	pk.Permutation = make([]int64, 3*pk.Domain[0].Cardinality)
 
-	dec := curve.NewDecoder(r)
+	dec := curve.NewDecoder(r, decOptions...)
 	toDecode := []interface{}{
 		(*[]fr.Element)(&pk.Ql),
 		(*[]fr.Element)(&pk.Qr),
@@ -173,20 +266,50 @@ func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
 			return n + dec.BytesRead(), err
 		}
 	}
+	n += dec.BytesRead()
 
-	return n + dec.BytesRead(), nil
+	if version >= 3 {
+		n2, err = pk.Vk.readFrom(r, version, decOptions...)
+		n += n2
+		if err != nil {
+			return n, err
+		}
+	}
+
+	computePermutationBigDomain(pk)
+
+	return n, nil
 
 }
 
-// WriteTo writes binary encoding of VerifyingKey to w
+// WriteTo writes binary encoding of VerifyingKey to w, in a container
+// points are compressed
+// use WriteRawTo(...) to encode the key without point compression
 func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
-	enc := curve.NewEncoder(w)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindVerifyingKey), payload(vk.writeTo, false))
+}
+
+// WriteRawTo writes binary encoding of VerifyingKey to w, in a container
+// points are not compressed
+// use WriteTo(...) to encode the key with point compression
+func (vk *VerifyingKey) WriteRawTo(w io.Writer) (n int64, err error) {
+	return gnarkio.WriteContainer(w, header(gnarkio.KindVerifyingKey), payload(vk.writeTo, true))
+}
+
+func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (n int64, err error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
 
 	toEncode := []interface{}{
 		vk.Size,
 		&vk.SizeInv,
 		&vk.Generator,
 		vk.NbPublicVariables,
+		&vk.CosetShift,
 		&vk.S[0],
 		&vk.S[1],
 		&vk.S[2],
@@ -195,6 +318,7 @@ func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
 		&vk.Qm,
 		&vk.Qo,
 		&vk.Qk,
+		vk.NoZeroKnowledge,
 	}
 
 	for _, v := range toEncode {
@@ -207,13 +331,37 @@ func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
 }
 
 // ReadFrom reads from binary representation in r into VerifyingKey
+// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
-	dec := curve.NewDecoder(r)
+	return readContainer(r, gnarkio.KindVerifyingKey, func(version uint16, r io.Reader) (int64, error) {
+		return vk.readFrom(r, version)
+	})
+}
+
+// UnsafeReadFrom has the same behavior as ReadFrom, except that it will not check that decode points
+// are on the curve and in the correct subgroup.
+func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return readContainer(r, gnarkio.KindVerifyingKey, func(version uint16, r io.Reader) (int64, error) {
+		return vk.readFrom(r, version, curve.NoSubgroupChecks())
+	})
+}
+
+func (vk *VerifyingKey) readFrom(r io.Reader, version uint16, decOptions ...func(*curve.Decoder)) (int64, error) {
+	dec := curve.NewDecoder(r, decOptions...)
 	toDecode := []interface{}{
 		&vk.Size,
 		&vk.SizeInv,
 		&vk.Generator,
 		&vk.NbPublicVariables,
+	}
+	// the bare encoding, before containers, doesn't have the coset shift: it is the
+	// multiplicative generator of the scalar field, set by Setup
+	if version == 0 {
+		vk.CosetShift.Set(&fft.NewDomain(1).FrMultiplicativeGen)
+	} else {
+		toDecode = append(toDecode, &vk.CosetShift)
+	}
+	toDecode = append(toDecode,
 		&vk.S[0],
 		&vk.S[1],
 		&vk.S[2],
@@ -222,6 +370,11 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 		&vk.Qm,
 		&vk.Qo,
 		&vk.Qk,
+	)
+	// before version 2, the verifying keys were all zero-knowledge
+	vk.NoZeroKnowledge = false
+	if version >= 2 {
+		toDecode = append(toDecode, &vk.NoZeroKnowledge)
 	}
 
 	for _, v := range toDecode {
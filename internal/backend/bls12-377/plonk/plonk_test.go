@@ -113,12 +113,27 @@ func BenchmarkProver(b *testing.B) {
 	}
 
 	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_, err = bls12_377plonk.Prove(ccs.(*cs.SparseR1CS), pk, fullWitness, backend.ProverConfig{})
-		if err != nil {
-			b.Fatal(err)
+	b.Run("prover", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err = bls12_377plonk.Prove(ccs.(*cs.SparseR1CS), pk, fullWitness, backend.ProverConfig{})
+			if err != nil {
+				b.Fatal(err)
+			}
 		}
-	}
+	})
+
+	b.Run("prover (reused buffers)", func(b *testing.B) {
+		prover := bls12_377plonk.NewProver(ccs.(*cs.SparseR1CS), pk, backend.ProverConfig{})
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err = prover.Prove(fullWitness)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
 
 func BenchmarkVerifier(b *testing.B) {
@@ -17,6 +17,7 @@
 package cs
 
 import (
+	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/fxamacker/cbor/v2"
@@ -82,7 +83,7 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]f
 	}
 
 	// keep track of wire that have a value
-	solution, err := newSolution(nbVariables, opt.HintFunctions, cs.Coefficients)
+	solution, err := newSolution(make([]fr.Element, nbVariables), opt.HintFunctions, cs.Coefficients)
 	if err != nil {
 		return solution.values, err
 	}
@@ -106,7 +107,7 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]f
 		coefficientsNegInv[i].Neg(&coefficientsNegInv[i])
 	}
 
-	if err := cs.parallelSolve(&solution, coefficientsNegInv); err != nil {
+	if err := cs.parallelSolve(&solution, coefficientsNegInv, opt.NbTasks); err != nil {
 		return solution.values, err
 	}
 
@@ -119,7 +120,7 @@ func (cs *SparseR1CS) Solve(witness []fr.Element, opt backend.ProverConfig) ([]f
 
 }
 
-func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.Element) error {
+func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.Element, nbTasks int) error {
 	// minWorkPerCPU is the minimum target number of constraint a task should hold
 	// in other words, if a level has less than minWorkPerCPU, it will not be parallelized and executed
 	// sequentially without sync.
@@ -128,14 +129,18 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 	// cs.Levels has a list of levels, where all constraints in a level l(n) are independent
 	// and may only have dependencies on previous levels
 
+	if nbTasks <= 0 {
+		nbTasks = runtime.NumCPU()
+	}
+
 	var wg sync.WaitGroup
-	chTasks := make(chan []int, runtime.NumCPU())
-	chError := make(chan error, runtime.NumCPU())
+	chTasks := make(chan []int, nbTasks)
+	chError := make(chan error, nbTasks)
 
 	// start a worker pool
 	// each worker wait on chTasks
 	// a task is a slice of constraint indexes to be solved
-	for i := 0; i < runtime.NumCPU(); i++ {
+	for i := 0; i < nbTasks; i++ {
 		go func() {
 			for t := range chTasks {
 				for _, i := range t {
@@ -191,7 +196,7 @@ func (cs *SparseR1CS) parallelSolve(solution *solution, coefficientsNegInv []fr.
 
 		// number of tasks for this level is set to num cpus
 		// but if we don't have enough work for all our CPUS, it can be lower.
-		nbTasks := runtime.NumCPU()
+		nbTasks := nbTasks
 		maxTasks := int(math.Ceil(maxCPU))
 		if nbTasks > maxTasks {
 			nbTasks = maxTasks
@@ -495,6 +500,16 @@ func (cs *SparseR1CS) CurveID() ecc.ID {
 	return ecc.BLS12_381
 }
 
+// WriteCircomR1CS returns an error: circom's .r1cs format only describes rank-1 constraint systems
+func (cs *SparseR1CS) WriteCircomR1CS(w io.Writer) (int64, error) {
+	return 0, errors.New("circom .r1cs export is only supported for R1CS")
+}
+
+// WriteCircomSymbols returns an error: circom's .r1cs format only describes rank-1 constraint systems
+func (cs *SparseR1CS) WriteCircomSymbols(w io.Writer) error {
+	return errors.New("circom .r1cs export is only supported for R1CS")
+}
+
 // WriteTo encodes SparseR1CS into provided io.Writer using cbor
 func (cs *SparseR1CS) WriteTo(w io.Writer) (int64, error) {
 	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
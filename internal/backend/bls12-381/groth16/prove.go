@@ -25,13 +25,17 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
 
+	"context"
+	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	bls12_381witness "github.com/consensys/gnark/internal/backend/bls12-381/witness"
 	"github.com/consensys/gnark/internal/utils"
+	"io"
 	"math/big"
 	"runtime"
+	"sync"
 )
 
 // Proof represents a Groth16 proof that was encoded with a ProvingKey and can be verified
@@ -52,75 +56,358 @@ func (proof *Proof) CurveID() ecc.ID {
 	return curve.ID
 }
 
-// Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
-func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt backend.ProverConfig) (*Proof, error) {
-	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
-		return nil, fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+// Prover generates proofs for a fixed R1CS and ProvingKey, reusing its buffers across
+// calls. It is safe for concurrent use.
+type Prover struct {
+	r1cs    *cs.R1CS
+	pk      *ProvingKey
+	opt     backend.ProverConfig
+	buffers sync.Pool // *proverBuffers
+}
+
+// proverBuffers holds the vectors a proof computation allocates
+type proverBuffers struct {
+	a, b, c                              []fr.Element // h is computed in place in a
+	wireValues, wireValuesA, wireValuesB []fr.Element
+}
+
+func newProverBuffers(r1cs *cs.R1CS, pk *ProvingKey) *proverBuffers {
+	nbWires := int(r1cs.NbPublicVariables + r1cs.NbSecretVariables + r1cs.NbInternalVariables)
+	return &proverBuffers{
+		a:           make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality),
+		b:           make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality),
+		c:           make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality),
+		wireValues:  make([]fr.Element, nbWires),
+		wireValuesA: make([]fr.Element, nbWires-int(pk.NbInfinityA)),
+		wireValuesB: make([]fr.Element, nbWires-int(pk.NbInfinityB)),
+	}
+}
+
+// NewProver returns a Prover for r1cs and pk, configured with opt
+func NewProver(r1cs *cs.R1CS, pk *ProvingKey, opt backend.ProverConfig) *Prover {
+	p := &Prover{r1cs: r1cs, pk: pk, opt: opt}
+	p.buffers.New = func() interface{} {
+		return newProverBuffers(r1cs, pk)
 	}
+	return p
+}
 
-	// solve the R1CS and compute the a, b, c vectors
-	a := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	b := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	c := make([]fr.Element, len(r1cs.Constraints), pk.Domain.Cardinality)
-	var wireValues []fr.Element
-	var err error
-	if wireValues, err = r1cs.Solve(witness, a, b, c, opt); err != nil {
-		if !opt.Force {
-			return nil, err
-		} else {
-			// we need to fill wireValues with random values else multi exps don't do much
-			var r fr.Element
-			_, _ = r.SetRandom()
-			for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < len(wireValues); i++ {
-				wireValues[i] = r
-				r.Double(&r)
-			}
+// Prove generates the proof of knoweldge of the r1cs with full witness (secret + public part).
+func (p *Prover) Prove(witness bls12_381witness.Witness) (*Proof, error) {
+	buffers := p.buffers.Get().(*proverBuffers)
+	proof, err := prove(p.r1cs, p.pk, witness, p.opt, buffers, &proverState{})
+	if err == nil {
+		// on error, some tasks may still be running; let the GC reclaim the buffers
+		p.buffers.Put(buffers)
+	}
+	return proof, err
+}
+
+// BatchProve generates a proof for each of witnesses. If witnesses[i] can't be proven, errs[i]
+// is its error and proofs[i] is nil.
+//
+// The witnesses are solved, and their H computed over the domain of pk, two at a time (see
+// backend.ProverConfig.BatchTasks). Then each multi-exponentiation of Prove is computed once for
+// all the solved witnesses, in a single pass over the points of pk (see batchMultiExpG1); the
+// vectors of all the witnesses are held in memory until then.
+func (p *Prover) BatchProve(witnesses []bls12_381witness.Witness) (proofs []*Proof, errs []error) {
+	r1cs, pk, opt := p.r1cs, p.pk, p.opt
+	ctx := opt.Context()
+	proofs, errs = make([]*Proof, len(witnesses)), make([]error, len(witnesses))
+
+	// solve the witnesses and compute their H
+	buffers := make([]*proverBuffers, len(witnesses))
+	wireValues, h := make([][]fr.Element, len(witnesses)), make([][]fr.Element, len(witnesses))
+	nbParallel, nbTasks := opt.BatchTasks(len(witnesses))
+	solveOpt := opt
+	if nbParallel > 1 {
+		solveOpt.NbTasks = nbTasks
+	}
+	utils.Parallelize(len(witnesses), func(start, end int) {
+		for i := start; i < end; i++ {
+			buffers[i] = p.buffers.Get().(*proverBuffers)
+			wireValues[i], h[i], errs[i] = solveAndComputeH(r1cs, pk, witnesses[i], buffers[i], solveOpt)
+		}
+	}, nbParallel)
+	defer func() {
+		for _, b := range buffers {
+			p.buffers.Put(b)
+		}
+	}()
+
+	var solved []int
+	for i := range witnesses {
+		if errs[i] == nil {
+			solved = append(solved, i)
+		}
+	}
+	setErr := func(err error) {
+		for _, i := range solved {
+			errs[i] = err
+		}
+	}
+	if len(solved) == 0 {
+		return proofs, errs
+	}
+	if err := ctx.Err(); err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+
+	// sample random r and s for each proof, and compute their r[δ], s[δ], kr[δ] together
+	r, s := make([]big.Int, len(solved)), make([]big.Int, len(solved))
+	deltaScalars := make([]fr.Element, 0, 3*len(solved))
+	for k := range solved {
+		var _r, _s, _kr fr.Element
+		if _, err := _r.SetRandom(); err != nil {
+			setErr(err)
+			return proofs, errs
+		}
+		if _, err := _s.SetRandom(); err != nil {
+			setErr(err)
+			return proofs, errs
 		}
+		_kr.Mul(&_r, &_s).Neg(&_kr)
+		_r.FromMont()
+		_s.FromMont()
+		_kr.FromMont()
+		_r.ToBigInt(&r[k])
+		_s.ToBigInt(&s[k])
+		deltaScalars = append(deltaScalars, _r, _s, _kr)
 	}
+	deltas := curve.BatchScalarMultiplicationG1(&pk.G1.Delta, deltaScalars)
 
-	// set the wire values in regular form
+	// each multi-exponentiation is computed for all the witnesses at once
+	n := opt.NbTasks
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+	scalarsA, scalarsB := make([][]fr.Element, len(solved)), make([][]fr.Element, len(solved))
+	scalarsK, scalarsZ := make([][]fr.Element, len(solved)), make([][]fr.Element, len(solved))
+	for k, i := range solved {
+		scalarsA[k], scalarsB[k] = buffers[i].wireValuesA, buffers[i].wireValuesB
+		scalarsK[k], scalarsZ[k] = wireValues[i][r1cs.NbPublicVariables:], h[i]
+	}
+	endMSM := opt.StartPhase("msm A")
+	ar, err := batchMultiExpG1(pk.G1.A, pk.precomputed.A, pk.precomputed.windowSize, scalarsA, n)
+	if err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+	endMSM(len(pk.G1.A))
+	endMSM = opt.StartPhase("msm B1")
+	bs1, err := batchMultiExpG1(pk.G1.B, pk.precomputed.B, pk.precomputed.windowSize, scalarsB, n)
+	if err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+	endMSM(len(pk.G1.B))
+	endMSM = opt.StartPhase("msm K")
+	krs, err := batchMultiExpG1(pk.G1.K, pk.precomputed.K, pk.precomputed.windowSize, scalarsK, n)
+	if err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+	endMSM(len(pk.G1.K))
+	endMSM = opt.StartPhase("msm Z")
+	krs2, err := batchMultiExpG1(pk.G1.Z, pk.precomputed.Z, pk.precomputed.windowSize, scalarsZ, n)
+	if err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+	endMSM(len(pk.G1.Z))
+	endMSM = opt.StartPhase("msm B2")
+	bs, err := batchMultiExpG2(pk.G2.B, pk.precomputed.G2B, pk.precomputed.windowSize, scalarsB, n)
+	if err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+	endMSM(len(pk.G2.B))
+	if err := ctx.Err(); err != nil {
+		setErr(err)
+		return proofs, errs
+	}
+
+	// finish the proofs as Prove does
+	for k, i := range solved {
+		proof := &Proof{}
+
+		ar[k].AddMixed(&pk.G1.Alpha)
+		ar[k].AddMixed(&deltas[3*k])
+		proof.Ar.FromJacobian(&ar[k])
+
+		bs1[k].AddMixed(&pk.G1.Beta)
+		bs1[k].AddMixed(&deltas[3*k+1])
+
+		var sAr, rBs1 curve.G1Jac
+		krs[k].AddMixed(&deltas[3*k+2])
+		krs[k].AddAssign(&krs2[k])
+		sAr.ScalarMultiplication(&ar[k], &s[k])
+		krs[k].AddAssign(&sAr)
+		rBs1.ScalarMultiplication(&bs1[k], &r[k])
+		krs[k].AddAssign(&rBs1)
+		proof.Krs.FromJacobian(&krs[k])
+
+		var deltaS curve.G2Jac
+		deltaS.FromAffine(&pk.G2.Delta)
+		deltaS.ScalarMultiplication(&deltaS, &s[k])
+		bs[k].AddAssign(&deltaS)
+		bs[k].AddMixed(&pk.G2.Beta)
+		proof.Bs.FromJacobian(&bs[k])
+
+		proofs[i] = proof
+	}
+	return proofs, errs
+}
+
+// solveAndComputeH solves the R1CS for witness in buffers, and returns its wire values, in
+// regular form, and H. The wire values are also filtered in buffers.wireValuesA and
+// buffers.wireValuesB.
+func solveAndComputeH(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, buffers *proverBuffers, opt backend.ProverConfig) (wireValues, h []fr.Element, err error) {
+	if err := checkProverInputs(r1cs, witness, opt); err != nil {
+		return nil, nil, err
+	}
+	ctx := opt.Context()
+	n := opt.NbTasks
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	if wireValues, err = solve(r1cs, witness, buffers, opt); err != nil {
+		return nil, nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
 	utils.Parallelize(len(wireValues), func(start, end int) {
 		for i := start; i < end; i++ {
 			wireValues[i].FromMont()
 		}
-	})
+	}, n)
 
-	// H (witness reduction / FFT part)
-	var h []fr.Element
-	chHDone := make(chan struct{}, 1)
-	go func() {
-		h = computeH(a, b, c, &pk.Domain)
-		a = nil
-		b = nil
-		c = nil
-		chHDone <- struct{}{}
-	}()
+	endFFT := opt.StartPhase("fft h")
+	h = computeH(ctx, buffers.a, buffers.b, buffers.c, &pk.Domain, n)
+	endFFT(int(pk.Domain.Cardinality))
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	endFilter := opt.StartPhase("filter wires A")
+	filterInfinity(buffers.wireValuesA, wireValues, pk.InfinityA)
+	endFilter(len(wireValues))
+	endFilter = opt.StartPhase("filter wires B")
+	filterInfinity(buffers.wireValuesB, wireValues, pk.InfinityB)
+	endFilter(len(wireValues))
+
+	return wireValues, h, nil
+}
+
+// checkProverInputs returns an error if witness can't be proven with opt
+func checkProverInputs(r1cs *cs.R1CS, witness bls12_381witness.Witness, opt backend.ProverConfig) error {
+	if opt.NoZeroKnowledge {
+		return errors.New("groth16 proofs are always zero-knowledge")
+	}
+	if len(witness) != int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables) {
+		return fmt.Errorf("invalid witness size, got %d, expected %d = %d (public - ONE_WIRE) + %d (secret)", len(witness), int(r1cs.NbPublicVariables-1+r1cs.NbSecretVariables), r1cs.NbPublicVariables, r1cs.NbSecretVariables)
+	}
+	return nil
+}
+
+// Prove generates the proof of knoweldge of a r1cs with full witness (secret + public part).
+func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt backend.ProverConfig) (*Proof, error) {
+	return prove(r1cs, pk, witness, opt, newProverBuffers(r1cs, pk), &proverState{})
+}
+
+// Resume completes a proof from a checkpoint saved by Prove with backend.WithCheckpoint, skipping
+// the computations it holds. It returns backend.ErrCheckpointMismatch if the checkpoint was saved
+// for another proving key or witness.
+func Resume(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, checkpoint io.Reader, opt backend.ProverConfig) (*Proof, error) {
+	state, err := readCheckpoint(checkpoint, r1cs, pk, witness)
+	if err != nil {
+		return nil, err
+	}
+	return prove(r1cs, pk, witness, opt, newProverBuffers(r1cs, pk), state)
+}
+
+// prove computes the parts of the proof that state doesn't hold yet, saving a checkpoint
+// once they are computed if opt.CheckpointPath is set
+func prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt backend.ProverConfig, buffers *proverBuffers, state *proverState) (*Proof, error) {
+	if err := checkProverInputs(r1cs, witness, opt); err != nil {
+		return nil, err
+	}
+	ctx := opt.Context()
+	n := opt.NbTasks
+	if n <= 0 {
+		n = runtime.NumCPU()
+	}
+
+	// rounds up to resumeRound are read from the checkpoint state was restored from, if any
+	resumeRound := state.round
+
+	// saveCheckpoint saves state at the end of round
+	saveCheckpoint := func(round int) error {
+		if opt.CheckpointPath == "" {
+			return nil
+		}
+		state.round = round
+		return writeCheckpoint(opt, pk, witness, state)
+	}
+
+	var wireValues, h []fr.Element
+	chHDone := make(chan error, 1)
+	if resumeRound < roundH {
+		// solve the R1CS and compute the a, b, c vectors
+		a, b, c := buffers.a, buffers.b, buffers.c
+		var err error
+		if wireValues, err = solve(r1cs, witness, buffers, opt); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// set the wire values in regular form
+		utils.Parallelize(len(wireValues), func(start, end int) {
+			for i := start; i < end; i++ {
+				wireValues[i].FromMont()
+			}
+		}, n)
+
+		// H (witness reduction / FFT part)
+		go func() {
+			endFFT := opt.StartPhase("fft h")
+			h = computeH(ctx, a, b, c, &pk.Domain, n)
+			endFFT(int(pk.Domain.Cardinality))
+			a = nil
+			b = nil
+			c = nil
+			if ctx.Err() != nil {
+				chHDone <- nil
+				return
+			}
+			state.wireValues, state.h = wireValues, h
+			chHDone <- saveCheckpoint(roundH)
+		}()
+	} else {
+		wireValues, h = state.wireValues, state.h
+		chHDone <- nil
+	}
 
 	// we need to copy and filter the wireValues for each multi exp
 	// as pk.G1.A, pk.G1.B and pk.G2.B may have (a significant) number of point at infinity
-	var wireValuesA, wireValuesB []fr.Element
+	wireValuesA, wireValuesB := buffers.wireValuesA, buffers.wireValuesB
 	chWireValuesA, chWireValuesB := make(chan struct{}, 1), make(chan struct{}, 1)
 
 	go func() {
-		wireValuesA = make([]fr.Element, len(wireValues)-int(pk.NbInfinityA))
-		for i, j := 0, 0; j < len(wireValuesA); i++ {
-			if pk.InfinityA[i] {
-				continue
-			}
-			wireValuesA[j] = wireValues[i]
-			j++
-		}
+		endFilter := opt.StartPhase("filter wires A")
+		filterInfinity(wireValuesA, wireValues, pk.InfinityA)
+		endFilter(len(wireValues))
 		close(chWireValuesA)
 	}()
 	go func() {
-		wireValuesB = make([]fr.Element, len(wireValues)-int(pk.NbInfinityB))
-		for i, j := 0, 0; j < len(wireValuesB); i++ {
-			if pk.InfinityB[i] {
-				continue
-			}
-			wireValuesB[j] = wireValues[i]
-			j++
-		}
+		endFilter := opt.StartPhase("filter wires B")
+		filterInfinity(wireValuesB, wireValues, pk.InfinityB)
+		endFilter(len(wireValues))
 		close(chWireValuesB)
 	}()
 
@@ -147,16 +434,22 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt
 	proof := &Proof{}
 	var bs1, ar curve.G1Jac
 
-	n := runtime.NumCPU()
+	// the G1 multi-exponentiations run 2 at a time
+	nbTasksG1 := n / 2
+	if nbTasksG1 == 0 {
+		nbTasksG1 = 1
+	}
 
 	chBs1Done := make(chan error, 1)
 	computeBS1 := func() {
 		<-chWireValuesB
-		if _, err := bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		endMSM := opt.StartPhase("msm B1")
+		if err := multiExpG1(&bs1, pk.G1.B, pk.precomputed.B, pk.precomputed.windowSize, wireValuesB, nbTasksG1); err != nil {
 			chBs1Done <- err
 			close(chBs1Done)
 			return
 		}
+		endMSM(len(wireValuesB))
 		bs1.AddMixed(&pk.G1.Beta)
 		bs1.AddMixed(&deltas[1])
 		chBs1Done <- nil
@@ -165,11 +458,13 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt
 	chArDone := make(chan error, 1)
 	computeAR1 := func() {
 		<-chWireValuesA
-		if _, err := ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		endMSM := opt.StartPhase("msm A")
+		if err := multiExpG1(&ar, pk.G1.A, pk.precomputed.A, pk.precomputed.windowSize, wireValuesA, nbTasksG1); err != nil {
 			chArDone <- err
 			close(chArDone)
 			return
 		}
+		endMSM(len(wireValuesA))
 		ar.AddMixed(&pk.G1.Alpha)
 		ar.AddMixed(&deltas[0])
 		proof.Ar.FromJacobian(&ar)
@@ -184,13 +479,17 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt
 		var krs, krs2, p1 curve.G1Jac
 		chKrs2Done := make(chan error, 1)
 		go func() {
-			_, err := krs2.MultiExp(pk.G1.Z, h, ecc.MultiExpConfig{NbTasks: n / 2})
+			endMSM := opt.StartPhase("msm Z")
+			err := multiExpG1(&krs2, pk.G1.Z, pk.precomputed.Z, pk.precomputed.windowSize, h, nbTasksG1)
+			endMSM(len(h))
 			chKrs2Done <- err
 		}()
-		if _, err := krs.MultiExp(pk.G1.K, wireValues[r1cs.NbPublicVariables:], ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
+		endMSM := opt.StartPhase("msm K")
+		if err := multiExpG1(&krs, pk.G1.K, pk.precomputed.K, pk.precomputed.windowSize, wireValues[r1cs.NbPublicVariables:], nbTasksG1); err != nil {
 			chKrsDone <- err
 			return
 		}
+		endMSM(len(wireValues) - int(r1cs.NbPublicVariables))
 		krs.AddMixed(&deltas[2])
 		n := 3
 		for n != 0 {
@@ -228,14 +527,16 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt
 		var Bs, deltaS curve.G2Jac
 
 		nbTasks := n
-		if nbTasks <= 16 {
+		if nbTasks <= 16 && opt.NbTasks <= 0 {
 			// if we don't have a lot of CPUs, this may artificially split the MSM
 			nbTasks *= 2
 		}
 		<-chWireValuesB
-		if _, err := Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks}); err != nil {
+		endMSM := opt.StartPhase("msm B2")
+		if err := multiExpG2(&Bs, pk.G2.B, pk.precomputed.G2B, pk.precomputed.windowSize, wireValuesB, nbTasks); err != nil {
 			return err
 		}
+		endMSM(len(wireValuesB))
 
 		deltaS.FromAffine(&pk.G2.Delta)
 		deltaS.ScalarMultiplication(&deltaS, &s)
@@ -247,7 +548,12 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt
 	}
 
 	// wait for FFT to end, as it uses all our CPUs
-	<-chHDone
+	if err := <-chHDone; err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// schedule our proof part computations
 	go computeKRS()
@@ -261,11 +567,92 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, witness bls12_381witness.Witness, opt
 	if err := <-chKrsDone; err != nil {
 		return nil, err
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	return proof, nil
 }
 
-func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
+// solve solves the R1CS in buffers and returns the wire values. If the witness doesn't satisfy
+// the R1CS and opt.Force is set, the wires it doesn't hold are set to random values.
+func solve(r1cs *cs.R1CS, witness bls12_381witness.Witness, buffers *proverBuffers, opt backend.ProverConfig) ([]fr.Element, error) {
+	endSolve := opt.StartPhase("solve")
+	wireValues, err := r1cs.SolveInto(witness, buffers.a, buffers.b, buffers.c, buffers.wireValues, opt)
+	if err != nil {
+		if !opt.Force {
+			return nil, err
+		}
+		// we need to fill wireValues with random values else multi exps don't do much
+		var r fr.Element
+		_, _ = r.SetRandom()
+		for i := r1cs.NbPublicVariables + r1cs.NbSecretVariables; i < len(wireValues); i++ {
+			wireValues[i] = r
+			r.Double(&r)
+		}
+	}
+	endSolve(len(r1cs.Constraints))
+	return wireValues, nil
+}
+
+// filterInfinity copies to dst the wire values whose point in the proving key isn't the point
+// at infinity, as flagged by infinity
+func filterInfinity(dst, wireValues []fr.Element, infinity []bool) {
+	for i, j := 0, 0; j < len(dst); i++ {
+		if infinity[i] {
+			continue
+		}
+		dst[j] = wireValues[i]
+		j++
+	}
+}
+
+// Rerandomize returns a proof of the same statement, unlinkable to proof: for random
+// r₁, r₂ ≠ 0,
+//
+//	A' = r₁⁻¹·A, B' = r₁·B + r₁r₂·[δ]2, C' = C + r₂·A
+//
+// such that e(A', B') = e(A, B)·e(r₂·A, [δ]2). The proof is not verified.
+func Rerandomize(proof *Proof, vk *VerifyingKey) (*Proof, error) {
+	var r1, r2 fr.Element
+	for _, r := range []*fr.Element{&r1, &r2} {
+		for r.IsZero() {
+			if _, err := r.SetRandom(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var r1Inv, r1r2 fr.Element
+	r1Inv.Inverse(&r1)
+	r1r2.Mul(&r1, &r2)
+	var r1Bi, r2Bi, r1InvBi, r1r2Bi big.Int
+	r1.ToBigIntRegular(&r1Bi)
+	r2.ToBigIntRegular(&r2Bi)
+	r1Inv.ToBigIntRegular(&r1InvBi)
+	r1r2.ToBigIntRegular(&r1r2Bi)
+
+	res := &Proof{}
+	res.Ar.ScalarMultiplication(&proof.Ar, &r1InvBi)
+
+	var bs, delta curve.G2Jac
+	bs.FromAffine(&proof.Bs)
+	bs.ScalarMultiplication(&bs, &r1Bi)
+	delta.FromAffine(&vk.G2.Delta)
+	delta.ScalarMultiplication(&delta, &r1r2Bi)
+	bs.AddAssign(&delta)
+	res.Bs.FromJacobian(&bs)
+
+	var krs curve.G1Jac
+	krs.FromAffine(&proof.Ar)
+	krs.ScalarMultiplication(&krs, &r2Bi)
+	krs.AddMixed(&proof.Krs)
+	res.Krs.FromJacobian(&krs)
+
+	return res, nil
+}
+
+// computeH returns nil if ctx is done before the FFTs complete
+func computeH(ctx context.Context, a, b, c []fr.Element, domain *fft.Domain, nbTasks int) []fr.Element {
 	// H part of Krs
 	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
 	// 	1 - _a = ifft(a), _b = ifft(b), _c = ifft(c)
@@ -274,20 +661,26 @@ func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
 
 	n := len(a)
 
-	// add padding to ensure input length is domain cardinality
-	padding := make([]fr.Element, int(domain.Cardinality)-n)
-	a = append(a, padding...)
-	b = append(b, padding...)
-	c = append(c, padding...)
+	// add padding to ensure input length is domain cardinality; a, b and c have enough capacity
+	a, b, c = a[:domain.Cardinality], b[:domain.Cardinality], c[:domain.Cardinality]
+	for i := n; i < len(a); i++ {
+		a[i], b[i], c[i] = fr.Element{}, fr.Element{}, fr.Element{}
+	}
 	n = len(a)
 
 	domain.FFTInverse(a, fft.DIF)
 	domain.FFTInverse(b, fft.DIF)
 	domain.FFTInverse(c, fft.DIF)
+	if ctx.Err() != nil {
+		return nil
+	}
 
 	domain.FFT(a, fft.DIT, true)
 	domain.FFT(b, fft.DIT, true)
 	domain.FFT(c, fft.DIT, true)
+	if ctx.Err() != nil {
+		return nil
+	}
 
 	var den, one fr.Element
 	one.SetOne()
@@ -302,7 +695,7 @@ func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
 				Sub(&a[i], &c[i]).
 				Mul(&a[i], &den)
 		}
-	})
+	}, nbTasks)
 
 	// ifft_coset
 	domain.FFTInverse(a, fft.DIF, true)
@@ -311,7 +704,7 @@ func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
 		for i := start; i < end; i++ {
 			a[i].FromMont()
 		}
-	})
+	}, nbTasks)
 
 	return a
 }
@@ -142,15 +142,16 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness bls12_381witness.Witne
 	}
 
 	// compute the folded commitment to H: Comm(h₁) + ζᵐ⁺²*Comm(h₂) + ζ²⁽ᵐ⁺²⁾*Comm(h₃)
-	mPlusTwo := big.NewInt(int64(vk.Size) + 2)
-	var zetaMPlusTwo fr.Element
-	zetaMPlusTwo.Exp(zeta, mPlusTwo)
-	var zetaMPlusTwoBigInt big.Int
-	zetaMPlusTwo.ToBigIntRegular(&zetaMPlusTwoBigInt)
+	// (ζᵐ in place of ζᵐ⁺² if the proofs are not blinded)
+	chunkSize := new(big.Int).SetUint64(vk.quotientChunkSize())
+	var zetaChunkSize fr.Element
+	zetaChunkSize.Exp(zeta, chunkSize)
+	var zetaChunkSizeBigInt big.Int
+	zetaChunkSize.ToBigIntRegular(&zetaChunkSizeBigInt)
 	foldedH := proof.H[2]
-	foldedH.ScalarMultiplication(&foldedH, &zetaMPlusTwoBigInt)
+	foldedH.ScalarMultiplication(&foldedH, &zetaChunkSizeBigInt)
 	foldedH.Add(&foldedH, &proof.H[1])
-	foldedH.ScalarMultiplication(&foldedH, &zetaMPlusTwoBigInt)
+	foldedH.ScalarMultiplication(&foldedH, &zetaChunkSizeBigInt)
 	foldedH.Add(&foldedH, &proof.H[0])
 
 	// Compute the commitment to the linearized polynomial
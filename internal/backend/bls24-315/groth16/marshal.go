@@ -19,20 +19,48 @@ package groth16
 import (
 	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
 	"io"
+
+	"github.com/consensys/gnark/backend"
+	gnarkio "github.com/consensys/gnark/io"
 )
 
-// WriteTo writes binary encoding of the Proof elements to writer
+// The objects are written in a container (see gnark/io), with the bare encoding of
+// writeTo as payload. ReadFrom also reads the bare encoding written by the versions of
+// gnark predating containers.
+
+// header returns the header of the container of an object of the given kind
+func header(kind gnarkio.Kind) gnarkio.Header {
+	return gnarkio.Header{Kind: kind, Backend: backend.GROTH16, Curve: curve.ID}
+}
+
+// payload returns the bare encoding of an object by writeTo, as payload of its container
+func payload(writeTo func(w io.Writer, raw bool) (int64, error), raw bool) io.WriterTo {
+	return gnarkio.WriterToFunc(func(w io.Writer) (int64, error) {
+		return writeTo(w, raw)
+	})
+}
+
+// readContainer reads the container of an object of the given kind, or its bare
+// encoding, with readPayload
+func readContainer(r io.Reader, kind gnarkio.Kind, readPayload func(r io.Reader) (int64, error)) (int64, error) {
+	_, n, err := gnarkio.ReadContainerOrLegacy(r, header(kind), func(_ gnarkio.Header, r io.Reader) (int64, error) {
+		return readPayload(r)
+	})
+	return n, err
+}
+
+// WriteTo writes binary encoding of the Proof elements to writer, in a container
 // points are stored in compressed form Ar | Krs | Bs
 // use WriteRawTo(...) to encode the proof without point compression
 func (proof *Proof) WriteTo(w io.Writer) (n int64, err error) {
-	return proof.writeTo(w, false)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProof), payload(proof.writeTo, false))
 }
 
-// WriteRawTo writes binary encoding of the Proof elements to writer
+// WriteRawTo writes binary encoding of the Proof elements to writer, in a container
 // points are stored in uncompressed form Ar | Krs | Bs
 // use WriteTo(...) to encode the proof with point compression
 func (proof *Proof) WriteRawTo(w io.Writer) (n int64, err error) {
-	return proof.writeTo(w, true)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProof), payload(proof.writeTo, true))
 }
 
 func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
@@ -58,7 +86,10 @@ func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
 // ReadFrom attempts to decode a Proof from reader
 // Proof must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
+	return readContainer(r, gnarkio.KindProof, proof.readFrom)
+}
 
+func (proof *Proof) readFrom(r io.Reader) (int64, error) {
 	dec := curve.NewDecoder(r)
 
 	if err := dec.Decode(&proof.Ar); err != nil {
@@ -74,18 +105,18 @@ func (proof *Proof) ReadFrom(r io.Reader) (n int64, err error) {
 	return dec.BytesRead(), nil
 }
 
-// WriteTo writes binary encoding of the key elements to writer
+// WriteTo writes binary encoding of the key elements to writer, in a container
 // points are compressed
 // use WriteRawTo(...) to encode the key without point compression
 func (vk *VerifyingKey) WriteTo(w io.Writer) (n int64, err error) {
-	return vk.writeTo(w, false)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindVerifyingKey), payload(vk.writeTo, false))
 }
 
-// WriteRawTo writes binary encoding of the key elements to writer
+// WriteRawTo writes binary encoding of the key elements to writer, in a container
 // points are not compressed
 // use WriteTo(...) to encode the key with point compression
 func (vk *VerifyingKey) WriteRawTo(w io.Writer) (n int64, err error) {
-	return vk.writeTo(w, true)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindVerifyingKey), payload(vk.writeTo, true))
 }
 
 // writeTo serialization format:
@@ -128,18 +159,22 @@ func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 }
 
 // ReadFrom attempts to decode a VerifyingKey from reader
-// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
-// serialization format:
+// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed),
+// or in the bare serialization format of bellman:
 // https://github.com/zkcrypto/bellman/blob/fa9be45588227a8c6ec34957de3f68705f07bd92/src/groth16/mod.rs#L143
 // [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,uint32(len(Kvk)),[Kvk]1
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
-	return vk.readFrom(r)
+	return readContainer(r, gnarkio.KindVerifyingKey, func(r io.Reader) (int64, error) {
+		return vk.readFrom(r)
+	})
 }
 
 // UnsafeReadFrom has the same behavior as ReadFrom, except that it will not check that decode points
 // are on the curve and in the correct subgroup.
 func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
-	return vk.readFrom(r, curve.NoSubgroupChecks())
+	return readContainer(r, gnarkio.KindVerifyingKey, func(r io.Reader) (int64, error) {
+		return vk.readFrom(r, curve.NoSubgroupChecks())
+	})
 }
 
 func (vk *VerifyingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
@@ -182,18 +217,18 @@ func (vk *VerifyingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)
 	return dec.BytesRead(), nil
 }
 
-// WriteTo writes binary encoding of the key elements to writer
+// WriteTo writes binary encoding of the key elements to writer, in a container
 // points are compressed
 // use WriteRawTo(...) to encode the key without point compression
 func (pk *ProvingKey) WriteTo(w io.Writer) (n int64, err error) {
-	return pk.writeTo(w, false)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProvingKey), payload(pk.writeTo, false))
 }
 
-// WriteRawTo writes binary encoding of the key elements to writer
+// WriteRawTo writes binary encoding of the key elements to writer, in a container
 // points are not compressed
 // use WriteTo(...) to encode the key with point compression
 func (pk *ProvingKey) WriteRawTo(w io.Writer) (n int64, err error) {
-	return pk.writeTo(w, true)
+	return gnarkio.WriteContainer(w, header(gnarkio.KindProvingKey), payload(pk.writeTo, true))
 }
 
 func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
@@ -242,16 +277,23 @@ func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
 // ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed)
 // note that we don't check that the points are on the curve or in the correct subgroup at this point
 func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
-	return pk.readFrom(r)
+	return readContainer(r, gnarkio.KindProvingKey, func(r io.Reader) (int64, error) {
+		return pk.readFrom(r)
+	})
 }
 
 // UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded points are on the curve
 // or in the correct subgroup
 func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
-	return pk.readFrom(r, curve.NoSubgroupChecks())
+	return readContainer(r, gnarkio.KindProvingKey, func(r io.Reader) (int64, error) {
+		return pk.readFrom(r, curve.NoSubgroupChecks())
+	})
 }
 
 func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	// tables precomputed from previous points are stale
+	pk.precomputed = fixedBaseTables{}
+
 	n, err := pk.Domain.ReadFrom(r)
 	if err != nil {
 		return n, err
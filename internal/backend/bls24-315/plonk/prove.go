@@ -18,6 +18,8 @@ package plonk
 
 import (
 	"crypto/sha256"
+	"errors"
+	"io"
 	"math/big"
 	"math/bits"
 	"runtime"
@@ -35,11 +37,16 @@ import (
 
 	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/internal/utils"
 )
 
+// errZeroKnowledgeMismatch is returned when the prover and the proving key disagree on the
+// blinding of the proof
+var errZeroKnowledgeMismatch = errors.New("backend.WithoutZeroKnowledge must be set if and only if the key was setup with backend.WithSetupWithoutZeroKnowledge")
+
 type Proof struct {
 
 	// Commitments to the solution vectors
@@ -58,8 +65,277 @@ type Proof struct {
 	ZShiftedOpening kzg.OpeningProof
 }
 
+// CurveID returns the curveID
+func (proof *Proof) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// Prover generates proofs for a fixed SparseR1CS and ProvingKey, reusing its buffers and
+// the witness independent evaluations across calls. It is safe for concurrent use.
+type Prover struct {
+	spr     *cs.SparseR1CS
+	pk      *ProvingKey
+	opt     backend.ProverConfig
+	data    *proverData
+	buffers sync.Pool // *proverBuffers
+}
+
+// proverData holds the values a proof computation needs that only depend on the proving key
+type proverData struct {
+	evaluationIDSmallDomain []fr.Element
+
+	// ql, qr, qm, qo evaluated on the big domain (coset), bit reversed
+	evalQl, evalQr, evalQm, evalQo []fr.Element
+
+	// L₁ evaluated on the big domain (coset), bit reversed, and (Xᵐ-1)⁻¹ evaluated on its coset
+	startsAtOne, evaluationXnMinusOneInverse []fr.Element
+}
+
+func newProverData(pk *ProvingKey) *proverData {
+	data := &proverData{
+		evaluationIDSmallDomain: getIDSmallDomain(&pk.Domain[0]),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(4)
+	evaluate := func(dst *[]fr.Element, poly []fr.Element) {
+		*dst = evaluateDomainBigBitReversed(poly, &pk.Domain[1], make([]fr.Element, pk.Domain[1].Cardinality))
+		wg.Done()
+	}
+	go evaluate(&data.evalQl, pk.Ql)
+	go evaluate(&data.evalQr, pk.Qr)
+	go evaluate(&data.evalQm, pk.Qm)
+	go evaluate(&data.evalQo, pk.Qo)
+
+	// computes L₁ (canonical form)
+	data.startsAtOne = make([]fr.Element, pk.Domain[1].Cardinality)
+	for i := 0; i < int(pk.Domain[0].Cardinality); i++ {
+		data.startsAtOne[i].Set(&pk.Domain[0].CardinalityInv)
+	}
+	pk.Domain[1].FFT(data.startsAtOne, fft.DIF, true)
+
+	// evaluate Z = Xᵐ-1 on a coset of the big domain
+	data.evaluationXnMinusOneInverse = fr.BatchInvert(evaluateXnMinusOneDomainBigCoset(&pk.Domain[1], &pk.Domain[0]))
+
+	wg.Wait()
+	return data
+}
+
+// proverBuffers holds the vectors a proof computation allocates on the small and big domains
+type proverBuffers struct {
+	l, r, o, qk                                     []fr.Element // small domain
+	evalL, evalR, evalO, evalZ, evalQk, ordering, h []fr.Element // big domain
+}
+
+func newProverBuffers(pk *ProvingKey) *proverBuffers {
+	small := func() []fr.Element { return make([]fr.Element, pk.Domain[0].Cardinality) }
+	big := func() []fr.Element { return make([]fr.Element, pk.Domain[1].Cardinality) }
+	return &proverBuffers{
+		l: small(), r: small(), o: small(), qk: small(),
+		evalL: big(), evalR: big(), evalO: big(), evalZ: big(),
+		evalQk: big(), ordering: big(), h: big(),
+	}
+}
+
+// NewProver returns a Prover for spr and pk, configured with opt. It evaluates the selector
+// polynomials of pk on the big domain once, and keeps these evaluations in memory.
+func NewProver(spr *cs.SparseR1CS, pk *ProvingKey, opt backend.ProverConfig) *Prover {
+	p := &Prover{spr: spr, pk: pk, opt: opt, data: newProverData(pk)}
+	p.buffers.New = func() interface{} {
+		return newProverBuffers(pk)
+	}
+	return p
+}
+
+// Prove from the public data
+func (p *Prover) Prove(fullWitness bls24_315witness.Witness) (*Proof, error) {
+	buffers := p.buffers.Get().(*proverBuffers)
+	proof, err := prove(p.spr, p.pk, fullWitness, p.opt, p.data, buffers, &proverState{}, commitSeparately(p.pk.Vk.KZGSRS))
+	if err == nil {
+		// on error, some tasks may still be running; let the GC reclaim the buffers
+		p.buffers.Put(buffers)
+	}
+	return proof, err
+}
+
+// BatchProve generates a proof for each of witnesses. If witnesses[i] can't be proven, errs[i]
+// is its error and proofs[i] is nil.
+//
+// The proofs run concurrently and in lockstep: the KZG commitments of each round are computed
+// together for all the proofs, in a single pass over the SRS (see batchCommitter), while the
+// FFTs of all the proofs share the domains of pk and the evaluations of the Prover. The
+// openings are computed separately.
+func (p *Prover) BatchProve(witnesses []bls24_315witness.Witness) (proofs []*Proof, errs []error) {
+	proofs, errs = make([]*Proof, len(witnesses)), make([]error, len(witnesses))
+	nbTasks := p.opt.NbTasks
+	if nbTasks <= 0 {
+		nbTasks = runtime.NumCPU()
+	}
+	committer := newBatchCommitter(p.pk.Vk.KZGSRS, nbTasks, len(witnesses))
+
+	// the proofs share the tasks outside of the commitments
+	opt := p.opt
+	if len(witnesses) > 1 {
+		opt.NbTasks = nbTasks / len(witnesses)
+		if opt.NbTasks < 1 {
+			opt.NbTasks = 1
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(witnesses))
+	for i := range witnesses {
+		go func(i int) {
+			defer wg.Done()
+			defer committer.leave()
+			buffers := p.buffers.Get().(*proverBuffers)
+			if proofs[i], errs[i] = prove(p.spr, p.pk, witnesses[i], opt, p.data, buffers, &proverState{}, committer.commit); errs[i] != nil {
+				proofs[i] = nil
+				return
+			}
+			p.buffers.Put(buffers)
+		}(i)
+	}
+	wg.Wait()
+	return proofs, errs
+}
+
+// batchCommitter computes the KZG commitments of the proofs of a batch, which run in lockstep:
+// each proof requests the commitments of a round with commit, which waits for all the running
+// proofs to request theirs, and then computes them together in a single pass over the SRS
+type batchCommitter struct {
+	srs       *kzg.SRS
+	nbTasks   int
+	lock      sync.Mutex
+	nbRunning int              // number of proofs of the batch which didn't return yet
+	requests  []*commitRequest // requests of the current round
+}
+
+// commitRequest is a call to batchCommitter.commit
+type commitRequest struct {
+	polynomials [][]fr.Element
+	digests     []kzg.Digest
+	err         error
+	done        chan struct{}
+}
+
+func newBatchCommitter(srs *kzg.SRS, nbTasks, nbProofs int) *batchCommitter {
+	return &batchCommitter{srs: srs, nbTasks: nbTasks, nbRunning: nbProofs}
+}
+
+// commit is the commitFunc of the proofs of the batch; the commitments of a round share the
+// tasks of the batch, nbTasks is ignored
+func (b *batchCommitter) commit(_ int, polynomials ...[]fr.Element) ([]kzg.Digest, error) {
+	request := &commitRequest{polynomials: polynomials, done: make(chan struct{})}
+	b.lock.Lock()
+	b.requests = append(b.requests, request)
+	round := b.completeRound()
+	b.lock.Unlock()
+
+	b.commitRound(round)
+	<-request.done
+	return request.digests, request.err
+}
+
+// leave is called when a proof of the batch returns, so that the others no longer wait for it
+func (b *batchCommitter) leave() {
+	b.lock.Lock()
+	b.nbRunning--
+	round := b.completeRound()
+	b.lock.Unlock()
+
+	b.commitRound(round)
+}
+
+// completeRound returns the requests of the current round and starts a new one if all the
+// running proofs requested their commitments, or nil. b.lock must be held.
+func (b *batchCommitter) completeRound() []*commitRequest {
+	if len(b.requests) == 0 || len(b.requests) < b.nbRunning {
+		return nil
+	}
+	round := b.requests
+	b.requests = nil
+	return round
+}
+
+// commitRound computes the commitments of the requests of a round
+func (b *batchCommitter) commitRound(round []*commitRequest) {
+	var polynomials [][]fr.Element
+	for _, request := range round {
+		for _, p := range request.polynomials {
+			if len(p) == 0 || len(p) > len(b.srs.G1) {
+				request.err = kzg.ErrInvalidPolynomialSize
+			}
+		}
+		if request.err == nil {
+			polynomials = append(polynomials, request.polynomials...)
+		}
+	}
+	digests := batchCommit(polynomials, b.srs, b.nbTasks)
+	for _, request := range round {
+		if request.err == nil {
+			request.digests, digests = digests[:len(request.polynomials)], digests[len(request.polynomials):]
+		}
+		close(request.done)
+	}
+}
+
+// batchCommit returns the KZG commitments of polynomials, in canonical form, as kzg.Commit
+// does, computed together in a single pass over the points of srs. Their sizes must be valid.
+func batchCommit(polynomials [][]fr.Element, srs *kzg.SRS, nbTasks int) []kzg.Digest {
+	if len(polynomials) == 0 {
+		return nil
+	}
+	size := 0
+	scalars := make([][]fr.Element, len(polynomials))
+	for i, p := range polynomials {
+		scalars[i] = make([]fr.Element, len(p))
+		utils.Parallelize(len(p), func(start, end int) {
+			for j := start; j < end; j++ {
+				scalars[i][j] = p[j]
+				scalars[i][j].FromMont()
+			}
+		}, nbTasks)
+		if len(p) > size {
+			size = len(p)
+		}
+	}
+	res := make([]curve.G1Jac, len(polynomials))
+	bucketMultiExpG1(res, srs.G1[:size], scalars, nbTasks)
+
+	digests := make([]kzg.Digest, len(res))
+	for i := range res {
+		digests[i].FromJacobian(&res[i])
+	}
+	return digests
+}
+
 // Prove from the public data
 func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witness, opt backend.ProverConfig) (*Proof, error) {
+	return prove(spr, pk, fullWitness, opt, newProverData(pk), newProverBuffers(pk), &proverState{}, commitSeparately(pk.Vk.KZGSRS))
+}
+
+// Resume completes a proof from a checkpoint saved by Prove with backend.WithCheckpoint, skipping
+// the rounds it holds. It returns backend.ErrCheckpointMismatch if the checkpoint was saved for
+// another proving key or witness.
+func Resume(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witness, checkpoint io.Reader, opt backend.ProverConfig) (*Proof, error) {
+	state, err := readCheckpoint(checkpoint, pk, fullWitness)
+	if err != nil {
+		return nil, err
+	}
+	return prove(spr, pk, fullWitness, opt, newProverData(pk), newProverBuffers(pk), state, commitSeparately(pk.Vk.KZGSRS))
+}
+
+// prove computes the rounds of the proof that state doesn't hold yet, saving a checkpoint
+// after each of them if opt.CheckpointPath is set. The KZG commitments of the rounds are
+// computed by commit.
+func prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witness, opt backend.ProverConfig, data *proverData, buffers *proverBuffers, state *proverState, commit commitFunc) (*Proof, error) {
+
+	ctx := opt.Context()
+	nbTasks := opt.NbTasks
+	if nbTasks <= 0 {
+		nbTasks = runtime.NumCPU()
+	}
 
 	// pick a hash function that will be used to derive the challenges
 	hFunc := sha256.New()
@@ -68,43 +344,77 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 	fs := fiatshamir.NewTranscript(hFunc, "gamma", "beta", "alpha", "zeta")
 
 	// result
-	proof := &Proof{}
+	proof := &state.proof
 
-	// compute the constraint system solution
-	var solution []fr.Element
-	var err error
-	if solution, err = spr.Solve(fullWitness, opt); err != nil {
-		if !opt.Force {
-			return nil, err
-		} else {
-			// we need to fill solution with random values
-			var r fr.Element
-			_, _ = r.SetRandom()
-			for i := spr.NbPublicVariables + spr.NbSecretVariables; i < len(solution); i++ {
-				solution[i] = r
-				r.Double(&r)
-			}
-		}
+	// the verifier folds the quotient according to the blinding of the proofs
+	if opt.NoZeroKnowledge != pk.Vk.NoZeroKnowledge {
+		return nil, errZeroKnowledgeMismatch
 	}
 
-	// query l, r, o in Lagrange basis, not blinded
-	evaluationLDomainSmall, evaluationRDomainSmall, evaluationODomainSmall := evaluateLROSmallDomain(spr, pk, solution)
+	// rounds up to resumeRound are read from the checkpoint state was restored from, if any
+	resumeRound := state.round
 
-	// save ll, lr, lo, and make a copy of them in canonical basis.
-	// note that we allocate more capacity to reuse for blinded polynomials
-	blindedLCanonical, blindedRCanonical, blindedOCanonical, err := computeBlindedLROCanonical(
-		evaluationLDomainSmall,
-		evaluationRDomainSmall,
-		evaluationODomainSmall,
-		&pk.Domain[0])
-	if err != nil {
-		return nil, err
+	// saveCheckpoint saves state at the end of round
+	saveCheckpoint := func(round int) error {
+		if opt.CheckpointPath == "" {
+			return nil
+		}
+		state.round = round
+		return writeCheckpoint(opt, pk, fullWitness, state)
 	}
 
-	// compute kzg commitments of bcl, bcr and bco
-	if err := commitToLRO(blindedLCanonical, blindedRCanonical, blindedOCanonical, proof, pk.Vk.KZGSRS); err != nil {
-		return nil, err
+	if resumeRound < roundLRO {
+		// compute the constraint system solution
+		endSolve := opt.StartPhase("solve")
+		var solution []fr.Element
+		var err error
+		if solution, err = spr.Solve(fullWitness, opt); err != nil {
+			if !opt.Force {
+				return nil, err
+			} else {
+				// we need to fill solution with random values
+				var r fr.Element
+				_, _ = r.SetRandom()
+				for i := spr.NbPublicVariables + spr.NbSecretVariables; i < len(solution); i++ {
+					solution[i] = r
+					r.Double(&r)
+				}
+			}
+		}
+		endSolve(len(spr.Constraints))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// query l, r, o in Lagrange basis, not blinded
+		endLRO := opt.StartPhase("fft lro")
+		state.l, state.r, state.o = evaluateLROSmallDomain(spr, pk, solution, buffers.l, buffers.r, buffers.o)
+
+		// save ll, lr, lo, and make a copy of them in canonical basis.
+		// note that we allocate more capacity to reuse for blinded polynomials
+		state.bl, state.br, state.bo, err = computeBlindedLROCanonical(state.l, state.r, state.o, &pk.Domain[0], pk.Vk.NoZeroKnowledge)
+		if err != nil {
+			return nil, err
+		}
+		endLRO(int(pk.Domain[0].Cardinality))
+
+		// compute kzg commitments of bcl, bcr and bco
+		endCommit := opt.StartPhase("commit lro")
+		digests, err := commit(nbTasks, state.bl, state.br, state.bo)
+		if err != nil {
+			return nil, err
+		}
+		copy(proof.LRO[:], digests)
+		endCommit(3 * len(state.bl))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := saveCheckpoint(roundLRO); err != nil {
+			return nil, err
+		}
 	}
+	evaluationLDomainSmall, evaluationRDomainSmall, evaluationODomainSmall := state.l, state.r, state.o
+	blindedLCanonical, blindedRCanonical, blindedOCanonical := state.bl, state.br, state.bo
 
 	// derive gamma from the Comm(blinded cl), Comm(blinded cr), Comm(blinded co)
 	gamma, err := deriveRandomness(&fs, "gamma", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
@@ -123,13 +433,22 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 	var blindedZCanonical []fr.Element
 	chZ := make(chan error, 1)
 	var alpha fr.Element
+	endConstraints := opt.StartPhase("fft constraints")
 	go func() {
 		var err error
+		if resumeRound >= roundZ {
+			blindedZCanonical = state.bz
+			alpha, err = deriveRandomness(&fs, "alpha", &proof.Z)
+			chZ <- err
+			close(chZ)
+			return
+		}
+		endZ := opt.StartPhase("permutation z")
 		blindedZCanonical, err = computeBlindedZCanonical(
 			evaluationLDomainSmall,
 			evaluationRDomainSmall,
 			evaluationODomainSmall,
-			pk, beta, gamma)
+			pk, data, beta, gamma, nbTasks)
 		if err != nil {
 			chZ <- err
 			close(chZ)
@@ -141,7 +460,21 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 		// this may add additional arithmetic operations, but with smaller tasks
 		// we ensure that this commitment is well parallelized, without having a "unbalanced task" making
 		// the rest of the code wait too long.
-		if proof.Z, err = kzg.Commit(blindedZCanonical, pk.Vk.KZGSRS, runtime.NumCPU()*2); err != nil {
+		// (unless the number of tasks is limited by the caller)
+		nbTasksZ := nbTasks
+		if opt.NbTasks <= 0 {
+			nbTasksZ *= 2
+		}
+		digests, err := commit(nbTasksZ, blindedZCanonical)
+		if err != nil {
+			chZ <- err
+			close(chZ)
+			return
+		}
+		proof.Z = digests[0]
+		endZ(len(blindedZCanonical))
+		state.bz = blindedZCanonical
+		if err := saveCheckpoint(roundZ); err != nil {
 			chZ <- err
 			close(chZ)
 			return
@@ -153,92 +486,124 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 		close(chZ)
 	}()
 
-	// evaluation of the blinded versions of l, r, o and bz
-	// on the coset of the big domain
-	var (
-		evaluationBlindedLDomainBigBitReversed []fr.Element
-		evaluationBlindedRDomainBigBitReversed []fr.Element
-		evaluationBlindedODomainBigBitReversed []fr.Element
-		evaluationBlindedZDomainBigBitReversed []fr.Element
-	)
-	chEvalBL := make(chan struct{}, 1)
-	chEvalBR := make(chan struct{}, 1)
-	chEvalBO := make(chan struct{}, 1)
-	go func() {
-		evaluationBlindedLDomainBigBitReversed = evaluateDomainBigBitReversed(blindedLCanonical, &pk.Domain[1])
-		close(chEvalBL)
-	}()
-	go func() {
-		evaluationBlindedRDomainBigBitReversed = evaluateDomainBigBitReversed(blindedRCanonical, &pk.Domain[1])
-		close(chEvalBR)
-	}()
-	go func() {
-		evaluationBlindedODomainBigBitReversed = evaluateDomainBigBitReversed(blindedOCanonical, &pk.Domain[1])
-		close(chEvalBO)
-	}()
-
-	var constraintsInd, constraintsOrdering []fr.Element
-	chConstraintInd := make(chan struct{}, 1)
-	go func() {
-		// compute qk in canonical basis, completed with the public inputs
-		qkCompletedCanonical := make([]fr.Element, pk.Domain[0].Cardinality)
-		copy(qkCompletedCanonical, fullWitness[:spr.NbPublicVariables])
-		copy(qkCompletedCanonical[spr.NbPublicVariables:], pk.LQk[spr.NbPublicVariables:])
-		pk.Domain[0].FFTInverse(qkCompletedCanonical, fft.DIF)
-		fft.BitReverse(qkCompletedCanonical)
-
-		// compute the evaluation of qlL+qrR+qmL.R+qoO+k on the coset of the big domain
-		// → uses the blinded version of l, r, o
-		<-chEvalBL
-		<-chEvalBR
-		<-chEvalBO
-		constraintsInd = evaluateConstraintsDomainBigBitReversed(
-			pk,
-			evaluationBlindedLDomainBigBitReversed,
-			evaluationBlindedRDomainBigBitReversed,
-			evaluationBlindedODomainBigBitReversed,
-			qkCompletedCanonical)
-		close(chConstraintInd)
-	}()
+	if resumeRound < roundQuotient {
+		// evaluation of the blinded versions of l, r, o and bz
+		// on the coset of the big domain
+		var (
+			evaluationBlindedLDomainBigBitReversed []fr.Element
+			evaluationBlindedRDomainBigBitReversed []fr.Element
+			evaluationBlindedODomainBigBitReversed []fr.Element
+			evaluationBlindedZDomainBigBitReversed []fr.Element
+		)
+		chEvalBL := make(chan struct{}, 1)
+		chEvalBR := make(chan struct{}, 1)
+		chEvalBO := make(chan struct{}, 1)
+		go func() {
+			evaluationBlindedLDomainBigBitReversed = evaluateDomainBigBitReversed(blindedLCanonical, &pk.Domain[1], buffers.evalL)
+			close(chEvalBL)
+		}()
+		go func() {
+			evaluationBlindedRDomainBigBitReversed = evaluateDomainBigBitReversed(blindedRCanonical, &pk.Domain[1], buffers.evalR)
+			close(chEvalBR)
+		}()
+		go func() {
+			evaluationBlindedODomainBigBitReversed = evaluateDomainBigBitReversed(blindedOCanonical, &pk.Domain[1], buffers.evalO)
+			close(chEvalBO)
+		}()
+
+		var constraintsInd, constraintsOrdering []fr.Element
+		chConstraintInd := make(chan struct{}, 1)
+		go func() {
+			// compute qk in canonical basis, completed with the public inputs
+			qkCompletedCanonical := buffers.qk
+			copy(qkCompletedCanonical, fullWitness[:spr.NbPublicVariables])
+			copy(qkCompletedCanonical[spr.NbPublicVariables:], pk.LQk[spr.NbPublicVariables:])
+			pk.Domain[0].FFTInverse(qkCompletedCanonical, fft.DIF)
+			fft.BitReverse(qkCompletedCanonical)
+
+			// compute the evaluation of qlL+qrR+qmL.R+qoO+k on the coset of the big domain
+			// → uses the blinded version of l, r, o
+			<-chEvalBL
+			<-chEvalBR
+			<-chEvalBO
+			constraintsInd = evaluateConstraintsDomainBigBitReversed(
+				pk,
+				data,
+				evaluationBlindedLDomainBigBitReversed,
+				evaluationBlindedRDomainBigBitReversed,
+				evaluationBlindedODomainBigBitReversed,
+				qkCompletedCanonical,
+				buffers.evalQk,
+				nbTasks)
+			close(chConstraintInd)
+		}()
+
+		chConstraintOrdering := make(chan error, 1)
+		go func() {
+			if err := <-chZ; err != nil {
+				chConstraintOrdering <- err
+				return
+			}
 
-	chConstraintOrdering := make(chan error, 1)
-	go func() {
-		if err := <-chZ; err != nil {
-			chConstraintOrdering <- err
-			return
+			evaluationBlindedZDomainBigBitReversed = evaluateDomainBigBitReversed(blindedZCanonical, &pk.Domain[1], buffers.evalZ)
+			// compute zu*g1*g2*g3-z*f1*f2*f3 on the coset of the big domain
+			// evalL, evalO, evalR are the evaluations of the blinded versions of l, r, o.
+			<-chEvalBL
+			<-chEvalBR
+			<-chEvalBO
+			constraintsOrdering = evaluateOrderingDomainBigBitReversed(
+				pk,
+				evaluationBlindedZDomainBigBitReversed,
+				evaluationBlindedLDomainBigBitReversed,
+				evaluationBlindedRDomainBigBitReversed,
+				evaluationBlindedODomainBigBitReversed,
+				beta,
+				gamma,
+				buffers.ordering,
+				nbTasks)
+			chConstraintOrdering <- nil
+			close(chConstraintOrdering)
+		}()
+
+		if err := <-chConstraintOrdering; err != nil {
+			return nil, err
 		}
 
-		evaluationBlindedZDomainBigBitReversed = evaluateDomainBigBitReversed(blindedZCanonical, &pk.Domain[1])
-		// compute zu*g1*g2*g3-z*f1*f2*f3 on the coset of the big domain
-		// evalL, evalO, evalR are the evaluations of the blinded versions of l, r, o.
-		<-chEvalBL
-		<-chEvalBR
-		<-chEvalBO
-		constraintsOrdering = evaluateOrderingDomainBigBitReversed(
-			pk,
-			evaluationBlindedZDomainBigBitReversed,
-			evaluationBlindedLDomainBigBitReversed,
-			evaluationBlindedRDomainBigBitReversed,
-			evaluationBlindedODomainBigBitReversed,
-			beta,
-			gamma)
-		chConstraintOrdering <- nil
-		close(chConstraintOrdering)
-	}()
-
-	if err := <-chConstraintOrdering; err != nil {
-		return nil, err
-	}
-
-	<-chConstraintInd
+		<-chConstraintInd
+		endConstraints(int(pk.Domain[1].Cardinality))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-	// compute h in canonical form
-	h1, h2, h3 := computeQuotientCanonical(pk, constraintsInd, constraintsOrdering, evaluationBlindedZDomainBigBitReversed, alpha)
+		// compute h in canonical form
+		endQuotient := opt.StartPhase("quotient")
+		state.h1, state.h2, state.h3 = computeQuotientCanonical(pk, data, constraintsInd, constraintsOrdering, evaluationBlindedZDomainBigBitReversed, alpha, buffers.h, nbTasks)
+		endQuotient(int(pk.Domain[1].Cardinality))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-	// compute kzg commitments of h1, h2 and h3
-	if err := commitToQuotient(h1, h2, h3, proof, pk.Vk.KZGSRS); err != nil {
-		return nil, err
+		// compute kzg commitments of h1, h2 and h3
+		endCommit := opt.StartPhase("commit h")
+		digests, err := commit(nbTasks, state.h1, state.h2, state.h3)
+		if err != nil {
+			return nil, err
+		}
+		copy(proof.H[:], digests)
+		endCommit(len(state.h1) + len(state.h2) + len(state.h3))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := saveCheckpoint(roundQuotient); err != nil {
+			return nil, err
+		}
+	} else {
+		// the quotient was computed before the checkpoint; only alpha is needed from round Z
+		if err := <-chZ; err != nil {
+			return nil, err
+		}
 	}
+	h1, h2, h3 := state.h1, state.h2, state.h3
 
 	// derive zeta
 	zeta, err := deriveRandomness(&fs, "zeta", &proof.H[0], &proof.H[1], &proof.H[2])
@@ -266,6 +631,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 	// open blinded Z at zeta*z
 	var zetaShifted fr.Element
 	zetaShifted.Mul(&zeta, &pk.Vk.Generator)
+	endOpen := opt.StartPhase("open z")
 	proof.ZShiftedOpening, err = kzg.Open(
 		blindedZCanonical,
 		zetaShifted,
@@ -274,6 +640,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 	if err != nil {
 		return nil, err
 	}
+	endOpen(len(blindedZCanonical))
 
 	// blinded z evaluated at u*zeta
 	bzuzeta := proof.ZShiftedOpening.ClaimedValue
@@ -288,6 +655,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 	go func() {
 		// compute the linearization polynomial r at zeta (goal: save committing separately to z, ql, qr, qm, qo, k)
 		wgZetaEvals.Wait()
+		endLinearized := opt.StartPhase("linearized polynomial")
 		linearizedPolynomialCanonical = computeLinearizedPolynomial(
 			blzeta,
 			brzeta,
@@ -299,17 +667,22 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 			bzuzeta,
 			blindedZCanonical,
 			pk,
+			nbTasks,
 		)
 
 		// TODO this commitment is only necessary to derive the challenge, we should
 		// be able to avoid doing it and get the challenge in another way
-		linearizedPolynomialDigest, errLPoly = kzg.Commit(linearizedPolynomialCanonical, pk.Vk.KZGSRS)
+		var digests []kzg.Digest
+		if digests, errLPoly = commit(nbTasks, linearizedPolynomialCanonical); errLPoly == nil {
+			linearizedPolynomialDigest = digests[0]
+		}
+		endLinearized(len(linearizedPolynomialCanonical))
 		close(chLpoly)
 	}()
 
 	// foldedHDigest = Comm(h1) + ζᵐ⁺²*Comm(h2) + ζ²⁽ᵐ⁺²⁾*Comm(h3)
 	var bZetaPowerm, bSize big.Int
-	bSize.SetUint64(pk.Domain[0].Cardinality + 2) // +2 because of the masking (h of degree 3(n+2)-1)
+	bSize.SetUint64(pk.Vk.quotientChunkSize()) // +2 because of the masking (h of degree 3(n+2)-1), unless noZK
 	var zetaPowerm fr.Element
 	zetaPowerm.Exp(zeta, &bSize)
 	zetaPowerm.ToBigIntRegular(&bZetaPowerm)
@@ -328,14 +701,18 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 			foldedH[i].Mul(&foldedH[i], &zetaPowerm) // ζ²⁽ᵐ⁺²⁾*h3+h2*ζᵐ⁺²
 			foldedH[i].Add(&foldedH[i], &h1[i])      // ζ^{2(m+2)*h3+ζᵐ⁺²*h2 + h1
 		}
-	})
+	}, nbTasks)
 
 	<-chLpoly
 	if errLPoly != nil {
 		return nil, errLPoly
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Batch open the first list of polynomials
+	endOpen = opt.StartPhase("batch opening")
 	proof.BatchedProof, err = kzg.BatchOpenSinglePoint(
 		[][]fr.Element{
 			foldedH,
@@ -362,6 +739,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness bls24_315witness.Witn
 	if err != nil {
 		return nil, err
 	}
+	endOpen(len(foldedH))
 
 	return proof, nil
 
@@ -376,61 +754,39 @@ func eval(c []fr.Element, p fr.Element) fr.Element {
 	return r
 }
 
-// fills proof.LRO with kzg commits of bcl, bcr and bco
-func commitToLRO(bcl, bcr, bco []fr.Element, proof *Proof, srs *kzg.SRS) error {
-	n := runtime.NumCPU() / 2
-	var err0, err1, err2 error
-	chCommit0 := make(chan struct{}, 1)
-	chCommit1 := make(chan struct{}, 1)
-	go func() {
-		proof.LRO[0], err0 = kzg.Commit(bcl, srs, n)
-		close(chCommit0)
-	}()
-	go func() {
-		proof.LRO[1], err1 = kzg.Commit(bcr, srs, n)
-		close(chCommit1)
-	}()
-	if proof.LRO[2], err2 = kzg.Commit(bco, srs, n); err2 != nil {
-		return err2
-	}
-	<-chCommit0
-	<-chCommit1
-
-	if err0 != nil {
-		return err0
-	}
+// commitFunc returns the KZG commitments of polynomials, in canonical form, computed with
+// at most nbTasks tasks
+type commitFunc func(nbTasks int, polynomials ...[]fr.Element) ([]kzg.Digest, error)
 
-	return err1
-}
-
-func commitToQuotient(h1, h2, h3 []fr.Element, proof *Proof, srs *kzg.SRS) error {
-	n := runtime.NumCPU() / 2
-	var err0, err1, err2 error
-	chCommit0 := make(chan struct{}, 1)
-	chCommit1 := make(chan struct{}, 1)
-	go func() {
-		proof.H[0], err0 = kzg.Commit(h1, srs, n)
-		close(chCommit0)
-	}()
-	go func() {
-		proof.H[1], err1 = kzg.Commit(h2, srs, n)
-		close(chCommit1)
-	}()
-	if proof.H[2], err2 = kzg.Commit(h3, srs, n); err2 != nil {
-		return err2
-	}
-	<-chCommit0
-	<-chCommit1
-
-	if err0 != nil {
-		return err0
+// commitSeparately returns a commitFunc computing each commitment with kzg.Commit; several
+// commitments run concurrently, with half of the tasks each
+func commitSeparately(srs *kzg.SRS) commitFunc {
+	return func(nbTasks int, polynomials ...[]fr.Element) ([]kzg.Digest, error) {
+		if len(polynomials) > 1 {
+			nbTasks = halfTasks(nbTasks)
+		}
+		digests := make([]kzg.Digest, len(polynomials))
+		errs := make([]error, len(polynomials))
+		var wg sync.WaitGroup
+		wg.Add(len(polynomials))
+		for i := range polynomials {
+			go func(i int) {
+				digests[i], errs[i] = kzg.Commit(polynomials[i], srs, nbTasks)
+				wg.Done()
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+		return digests, nil
 	}
-
-	return err1
 }
 
-// computeBlindedLROCanonical l, r, o in canonical basis with blinding
-func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain) (bcl, bcr, bco []fr.Element, err error) {
+// computeBlindedLROCanonical l, r, o in canonical basis with blinding, or without it if noZK is set
+func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain, noZK bool) (bcl, bcr, bco []fr.Element, err error) {
 
 	// note that bcl, bcr and bco reuses cl, cr and co memory
 	cl := make([]fr.Element, domain.Cardinality, domain.Cardinality+2)
@@ -444,7 +800,7 @@ func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain) (bc
 		copy(cl, ll)
 		domain.FFTInverse(cl, fft.DIF)
 		fft.BitReverse(cl)
-		bcl, err = blindPoly(cl, domain.Cardinality, 1)
+		bcl, err = blindPoly(cl, domain.Cardinality, 1, noZK)
 		chDone <- err
 	}()
 	go func() {
@@ -452,13 +808,13 @@ func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain) (bc
 		copy(cr, lr)
 		domain.FFTInverse(cr, fft.DIF)
 		fft.BitReverse(cr)
-		bcr, err = blindPoly(cr, domain.Cardinality, 1)
+		bcr, err = blindPoly(cr, domain.Cardinality, 1, noZK)
 		chDone <- err
 	}()
 	copy(co, lo)
 	domain.FFTInverse(co, fft.DIF)
 	fft.BitReverse(co)
-	if bco, err = blindPoly(co, domain.Cardinality, 1); err != nil {
+	if bco, err = blindPoly(co, domain.Cardinality, 1, noZK); err != nil {
 		return
 	}
 	err = <-chDone
@@ -475,11 +831,15 @@ func computeBlindedLROCanonical(ll, lr, lo []fr.Element, domain *fft.Domain) (bc
 // * cp polynomial in canonical form
 // * rou root of unity, meaning the blinding factor is multiple of X**rou-1
 // * bo blinding order,  it's the degree of Q, where the blinding is Q(X)*(X**degree-1)
+// * noZK if set, cp is returned as is
 //
 // WARNING:
 // pre condition degree(cp) ⩽ rou + bo
 // pre condition cap(cp) ⩾ int(totalDegree + 1)
-func blindPoly(cp []fr.Element, rou, bo uint64) ([]fr.Element, error) {
+func blindPoly(cp []fr.Element, rou, bo uint64, noZK bool) ([]fr.Element, error) {
+	if noZK {
+		return cp, nil
+	}
 
 	// degree of the blinded polynomial is max(rou+order, cp.Degree)
 	totalDegree := rou + bo
@@ -505,16 +865,12 @@ func blindPoly(cp []fr.Element, rou, bo uint64) ([]fr.Element, error) {
 
 }
 
-// evaluateLROSmallDomain extracts the solution l, r, o, and returns it in lagrange form.
+// evaluateLROSmallDomain extracts the solution l, r, o in l, r, o, and returns it in lagrange form.
 // solution = [ public | secret | internal ]
-func evaluateLROSmallDomain(spr *cs.SparseR1CS, pk *ProvingKey, solution []fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
+func evaluateLROSmallDomain(spr *cs.SparseR1CS, pk *ProvingKey, solution, l, r, o []fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
 
 	s := int(pk.Domain[0].Cardinality)
 
-	var l, r, o []fr.Element
-	l = make([]fr.Element, s)
-	r = make([]fr.Element, s)
-	o = make([]fr.Element, s)
 	s0 := solution[0]
 
 	for i := 0; i < spr.NbPublicVariables; i++ { // placeholders
@@ -549,7 +905,7 @@ func evaluateLROSmallDomain(spr *cs.SparseR1CS, pk *ProvingKey, solution []fr.El
 //								     (l(g^k)+β*s1(g^k)+γ)*(r(g^k)+β*s2(g^k)+γ)*(o(g^k)+β*s3(\g^k)+γ)
 //
 //	* l, r, o are the solution in Lagrange basis, evaluated on the small domain
-func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma fr.Element) ([]fr.Element, error) {
+func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, data *proverData, beta, gamma fr.Element, nbTasks int) ([]fr.Element, error) {
 
 	// note that z has more capacity has its memory is reused for blinded z later on
 	z := make([]fr.Element, pk.Domain[0].Cardinality, pk.Domain[0].Cardinality+3)
@@ -559,7 +915,7 @@ func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma
 	z[0].SetOne()
 	gInv[0].SetOne()
 
-	evaluationIDSmallDomain := getIDSmallDomain(&pk.Domain[0])
+	evaluationIDSmallDomain := data.evaluationIDSmallDomain
 
 	utils.Parallelize(nbElmts-1, func(start, end int) {
 
@@ -582,7 +938,7 @@ func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma
 			gInv[i+1] = g[0]
 			z[i+1] = f[0]
 		}
-	})
+	}, nbTasks)
 
 	gInv = fr.BatchInvert(gInv)
 	for i := 1; i < nbElmts; i++ {
@@ -593,7 +949,7 @@ func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma
 	pk.Domain[0].FFTInverse(z, fft.DIF)
 	fft.BitReverse(z)
 
-	return blindPoly(z, pk.Domain[0].Cardinality, 2)
+	return blindPoly(z, pk.Domain[0].Cardinality, 2, pk.Vk.NoZeroKnowledge)
 
 }
 
@@ -602,29 +958,10 @@ func computeBlindedZCanonical(l, r, o []fr.Element, pk *ProvingKey, beta, gamma
 //
 // * evalL, evalR, evalO are the evaluation of the blinded solution vectors on odd cosets
 // * qk is the completed version of qk, in canonical version
-func evaluateConstraintsDomainBigBitReversed(pk *ProvingKey, evalL, evalR, evalO, qk []fr.Element) []fr.Element {
-	var evalQl, evalQr, evalQm, evalQo, evalQk []fr.Element
-	var wg sync.WaitGroup
-	wg.Add(4)
-
-	go func() {
-		evalQl = evaluateDomainBigBitReversed(pk.Ql, &pk.Domain[1])
-		wg.Done()
-	}()
-	go func() {
-		evalQr = evaluateDomainBigBitReversed(pk.Qr, &pk.Domain[1])
-		wg.Done()
-	}()
-	go func() {
-		evalQm = evaluateDomainBigBitReversed(pk.Qm, &pk.Domain[1])
-		wg.Done()
-	}()
-	go func() {
-		evalQo = evaluateDomainBigBitReversed(pk.Qo, &pk.Domain[1])
-		wg.Done()
-	}()
-	evalQk = evaluateDomainBigBitReversed(qk, &pk.Domain[1])
-	wg.Wait()
+// * res is where the evaluation is stored, of size the big domain cardinality
+func evaluateConstraintsDomainBigBitReversed(pk *ProvingKey, data *proverData, evalL, evalR, evalO, qk, res []fr.Element, nbTasks int) []fr.Element {
+	evalQl, evalQr, evalQm, evalQo := data.evalQl, data.evalQr, data.evalQm, data.evalQo
+	evalQk := evaluateDomainBigBitReversed(qk, &pk.Domain[1], res)
 
 	// computes the evaluation of qrR+qlL+qmL.R+qoO+k on the coset of the big domain
 	utils.Parallelize(len(evalQk), func(start, end int) {
@@ -641,7 +978,7 @@ func evaluateConstraintsDomainBigBitReversed(pk *ProvingKey, evalL, evalR, evalO
 			t0.Add(&t0, &t1)               // ql.l + qr.r + qm.l.r + qo.o
 			evalQk[i].Add(&t0, &evalQk[i]) // ql.l + qr.r + qm.l.r + qo.o + k
 		}
-	})
+	}, nbTasks)
 
 	return evalQk
 }
@@ -652,13 +989,13 @@ func evaluateConstraintsDomainBigBitReversed(pk *ProvingKey, evalL, evalR, evalO
 // * z evaluation of the blinded permutation accumulator polynomial on odd cosets
 // * l, r, o evaluation of the blinded solution vectors on odd cosets
 // * gamma randomization
-func evaluateOrderingDomainBigBitReversed(pk *ProvingKey, z, l, r, o []fr.Element, beta, gamma fr.Element) []fr.Element {
+// * res is where the evaluation is stored, of size the big domain cardinality
+func evaluateOrderingDomainBigBitReversed(pk *ProvingKey, z, l, r, o []fr.Element, beta, gamma fr.Element, res []fr.Element, nbTasks int) []fr.Element {
 
 	nbElmts := int(pk.Domain[1].Cardinality)
 
 	// computes  z_(uX)*(l(X)+s₁(X)*β+γ)*(r(X))+s₂(gⁱ)*β+γ)*(o(X))+s₃(X)*β+γ) - z(X)*(l(X)+X*β+γ)*(r(X)+u*X*β+γ)*(o(X)+u²*X*β+γ)
 	// on the big domain (coset).
-	res := make([]fr.Element, pk.Domain[1].Cardinality)
 
 	nn := uint64(64 - bits.TrailingZeros64(uint64(nbElmts)))
 
@@ -699,7 +1036,7 @@ func evaluateOrderingDomainBigBitReversed(pk *ProvingKey, z, l, r, o []fr.Elemen
 
 			evaluationIDBigDomain.Mul(&evaluationIDBigDomain, &pk.Domain[1].Generator) // gⁱ*g
 		}
-	})
+	}, nbTasks)
 
 	return res
 }
@@ -709,9 +1046,11 @@ func evaluateOrderingDomainBigBitReversed(pk *ProvingKey, z, l, r, o []fr.Elemen
 //
 // Puts the result in res of size n.
 // Warning: result is in bit reversed order, we do a bit reverse operation only once in computeQuotientCanonical
-func evaluateDomainBigBitReversed(poly []fr.Element, domainH *fft.Domain) []fr.Element {
-	res := make([]fr.Element, domainH.Cardinality)
+func evaluateDomainBigBitReversed(poly []fr.Element, domainH *fft.Domain, res []fr.Element) []fr.Element {
 	copy(res, poly)
+	for i := len(poly); i < len(res); i++ {
+		res[i].SetZero()
+	}
 	domainH.FFT(res, fft.DIF, true)
 	return res
 }
@@ -747,20 +1086,11 @@ func evaluateXnMinusOneDomainBigCoset(domainBig, domainSmall *fft.Domain) []fr.E
 // ql(X)L(X)+qr(X)R(X)+qm(X)L(X)R(X)+qo(X)O(X)+k(X) + α.(z(μX)*g₁(X)*g₂(X)*g₃(X)-z(X)*f₁(X)*f₂(X)*f₃(X)) + α²*L₁(X)*(Z(X)-1)= h(X)Z(X)
 //
 // constraintInd, constraintOrdering are evaluated on the big domain (coset).
-func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReversed, evaluationConstraintOrderingBitReversed, evaluationBlindedZDomainBigBitReversed []fr.Element, alpha fr.Element) ([]fr.Element, []fr.Element, []fr.Element) {
-
-	h := make([]fr.Element, pk.Domain[1].Cardinality)
-
-	// evaluate Z = Xᵐ-1 on a coset of the big domain
-	evaluationXnMinusOneInverse := evaluateXnMinusOneDomainBigCoset(&pk.Domain[1], &pk.Domain[0])
-	evaluationXnMinusOneInverse = fr.BatchInvert(evaluationXnMinusOneInverse)
+// h, of size the big domain cardinality, is where the quotient is computed.
+func computeQuotientCanonical(pk *ProvingKey, data *proverData, evaluationConstraintsIndBitReversed, evaluationConstraintOrderingBitReversed, evaluationBlindedZDomainBigBitReversed []fr.Element, alpha fr.Element, h []fr.Element, nbTasks int) ([]fr.Element, []fr.Element, []fr.Element) {
 
-	// computes L₁ (canonical form)
-	startsAtOne := make([]fr.Element, pk.Domain[1].Cardinality)
-	for i := 0; i < int(pk.Domain[0].Cardinality); i++ {
-		startsAtOne[i].Set(&pk.Domain[0].CardinalityInv)
-	}
-	pk.Domain[1].FFT(startsAtOne, fft.DIF, true)
+	evaluationXnMinusOneInverse := data.evaluationXnMinusOneInverse
+	startsAtOne := data.startsAtOne
 
 	// ql(X)L(X)+qr(X)R(X)+qm(X)L(X)R(X)+qo(X)O(X)+k(X) + α.(z(μX)*g₁(X)*g₂(X)*g₃(X)-z(X)*f₁(X)*f₂(X)*f₃(X)) + α**2*L₁(X)(Z(X)-1)
 	// on a coset of the big domain
@@ -784,16 +1114,17 @@ func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReverse
 				Add(&h[_i], &evaluationConstraintsIndBitReversed[_i]).
 				Mul(&h[_i], &evaluationXnMinusOneInverse[i%ratio])
 		}
-	})
+	}, nbTasks)
 
 	// put h in canonical form. h is of degree 3*(n+1)+2.
 	// using fft.DIT put h revert bit reverse
 	pk.Domain[1].FFTInverse(h, fft.DIT, true)
 
-	// degree of hi is n+2 because of the blinding
-	h1 := h[:pk.Domain[0].Cardinality+2]
-	h2 := h[pk.Domain[0].Cardinality+2 : 2*(pk.Domain[0].Cardinality+2)]
-	h3 := h[2*(pk.Domain[0].Cardinality+2) : 3*(pk.Domain[0].Cardinality+2)]
+	// degree of hi is n+2 because of the blinding (n without it)
+	m := pk.Vk.quotientChunkSize()
+	h1 := h[:m]
+	h2 := h[m : 2*m]
+	h3 := h[2*m : 3*m]
 
 	return h1, h2, h3
 
@@ -810,7 +1141,7 @@ func computeQuotientCanonical(pk *ProvingKey, evaluationConstraintsIndBitReverse
 // α²*L₁(ζ)*Z(X)
 // + α*( (l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*Z(μζ)*s3(X) - Z(X)*(l(ζ)+β*id1(ζ)+γ)*(r(ζ)+β*id2(ζ)+γ)*(o(ζ)+β*id3(ζ)+γ))
 // + l(ζ)*Ql(X) + l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X)
-func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, blindedZCanonical []fr.Element, pk *ProvingKey) []fr.Element {
+func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, blindedZCanonical []fr.Element, pk *ProvingKey, nbTasks int) []fr.Element {
 
 	// first part: individual constraints
 	var rl fr.Element
@@ -893,7 +1224,96 @@ func computeLinearizedPolynomial(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta,
 			t0.Mul(&blindedZCanonical[i], &lagrangeZeta)
 			linPol[i].Add(&linPol[i], &t0) // finish the computation
 		}
-	})
+	}, nbTasks)
 
 	return linPol
 }
+
+// halfTasks returns the number of tasks of each of 2 concurrent multi-exponentiations
+func halfTasks(nbTasks int) int {
+	if nbTasks < 2 {
+		return 1
+	}
+	return nbTasks / 2
+}
+
+// digit returns the c bits of the regular form scalar k starting at bit pos
+func digit(k *fr.Element, pos, c int) int {
+	i, shift := pos/64, uint(pos%64)
+	d := k[i] >> shift
+	if int(shift)+c > 64 && i+1 < fr.Limbs {
+		d |= k[i+1] << (64 - shift)
+	}
+	return int(d & ((1 << uint(c)) - 1))
+}
+
+// batchWindowSize returns the window size minimizing the number of additions of a bucket
+// multi-exponentiation of nbPoints points, about (fr.Bits / c) * (nbPoints + 2^c) per vector
+func batchWindowSize(nbPoints int) int {
+	best, bestCost := 2, -1
+	for c := 2; c <= 16; c++ {
+		cost := ((fr.Bits + c - 1) / c) * (nbPoints + (1 << uint(c)))
+		if bestCost < 0 || cost < bestCost {
+			best, bestCost = c, cost
+		}
+	}
+	return best
+}
+
+// bucketMultiExpG1 sets res[v] to the multi-exponentiation of bases and scalars[v] (in
+// regular form), which may be shorter than bases, for each vector v.
+//
+// The scalars are split in windows of c bits. Each task accumulates the digits of some windows
+// of all the vectors, in its 2^c - 1 buckets per vector: each point is read once for all the
+// vectors. The sums of the windows are then combined by doubling.
+func bucketMultiExpG1(res []curve.G1Jac, bases []curve.G1Affine, scalars [][]fr.Element, nbTasks int) {
+	c := batchWindowSize(len(bases))
+	nbChunks := (fr.Bits + c - 1) / c
+	windowSums := make([][]curve.G1Jac, nbChunks)
+	utils.Parallelize(nbChunks, func(start, end int) {
+		buckets := make([][]curve.G1Jac, len(scalars))
+		for v := range buckets {
+			buckets[v] = make([]curve.G1Jac, (1<<uint(c))-1)
+		}
+		for chunk := start; chunk < end; chunk++ {
+			for v := range buckets {
+				for k := range buckets[v] {
+					buckets[v][k] = curve.G1Jac{}
+				}
+			}
+			for i := range bases {
+				for v := range scalars {
+					if i >= len(scalars[v]) {
+						continue
+					}
+					if d := digit(&scalars[v][i], chunk*c, c); d != 0 {
+						buckets[v][d-1].AddMixed(&bases[i])
+					}
+				}
+			}
+			windowSums[chunk] = make([]curve.G1Jac, len(scalars))
+			for v := range buckets {
+				reduceBucketsG1(&windowSums[chunk][v], buckets[v])
+			}
+		}
+	}, nbTasks)
+
+	for v := range res {
+		res[v] = windowSums[nbChunks-1][v]
+		for chunk := nbChunks - 2; chunk >= 0; chunk-- {
+			for k := 0; k < c; k++ {
+				res[v].DoubleAssign()
+			}
+			res[v].AddAssign(&windowSums[chunk][v])
+		}
+	}
+}
+
+// reduceBucketsG1 sets res to Σ (k+1) * buckets[k]
+func reduceBucketsG1(res *curve.G1Jac, buckets []curve.G1Jac) {
+	var runningSum curve.G1Jac
+	for k := len(buckets) - 1; k >= 0; k-- {
+		runningSum.AddAssign(&buckets[k])
+		res.AddAssign(&runningSum)
+	}
+}
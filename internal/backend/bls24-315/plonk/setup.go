@@ -17,13 +17,20 @@
 package plonk
 
 import (
+	"context"
 	"errors"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/kzg"
 	"github.com/consensys/gnark/internal/backend/bls24-315/cs"
+	"io"
 
+	"github.com/consensys/gnark-crypto/ecc"
 	kzgg "github.com/consensys/gnark-crypto/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/internal/backend/compiled"
+	gnarkio "github.com/consensys/gnark/io"
 )
 
 // ProvingKey stores the data needed to generate a proof:
@@ -83,12 +90,147 @@ type VerifyingKey struct {
 	// Commitments to ql, qr, qm, qo prepended with as many zeroes (ones for l) as there are public inputs.
 	// In particular Qk is not complete.
 	Ql, Qr, Qm, Qo, Qk kzg.Digest
+
+	// NoZeroKnowledge is set if the key was setup with backend.WithSetupWithoutZeroKnowledge:
+	// the polynomials of the proofs are not blinded, and the proofs don't hide the witness.
+	NoZeroKnowledge bool
+}
+
+// quotientChunkSize returns the size of the parts h₁, h₂, h₃ of the quotient: h is of degree
+// 3(n+1)+2 with the blinding, and 3n-4 without
+func (vk *VerifyingKey) quotientChunkSize() uint64 {
+	if vk.NoZeroKnowledge {
+		return vk.Size
+	}
+	return vk.Size + 2
 }
 
 // Setup sets proving and verifying keys
 func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error) {
+	return SetupContext(context.Background(), spr, srs, backend.SetupConfig{})
+}
+
+// SetupContext behaves like Setup, and returns ctx.Err() if ctx is done between the
+// FFTs and the commitments to the polynomials
+func SetupContext(ctx context.Context, spr *cs.SparseR1CS, srs *kzg.SRS, opt backend.SetupConfig) (*ProvingKey, *VerifyingKey, error) {
+	pk, err := initKeys(spr, srs, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	vk := pk.Vk
+
+	// build permutation. Note: at this stage, the permutation takes in account the placeholders
+	buildPermutation(spr, pk)
+
+	// public polynomials corresponding to constraints: [ placholders | constraints | assertions ]
+	// and permutation polynomials s1, s2, s3
+	polynomials := setupPolynomials(spr, pk)
+	pk.Ql = polynomials[0]()
+	pk.Qr = polynomials[1]()
+	pk.Qm = polynomials[2]()
+	pk.Qo = polynomials[3]()
+	pk.CQk = polynomials[4]()
+	pk.LQk = polynomials[5]()
+	pk.S1Canonical = polynomials[6]()
+	pk.S2Canonical = polynomials[7]()
+	pk.S3Canonical = polynomials[8]()
+	computePermutationBigDomain(pk)
+
+	// Commit to the polynomials to set up the verifying key
+	commitments := []struct {
+		digest *kzg.Digest
+		p      []fr.Element
+	}{
+		{&vk.Ql, pk.Ql},
+		{&vk.Qr, pk.Qr},
+		{&vk.Qm, pk.Qm},
+		{&vk.Qo, pk.Qo},
+		{&vk.Qk, pk.CQk},
+		{&vk.S[0], pk.S1Canonical},
+		{&vk.S[1], pk.S2Canonical},
+		{&vk.S[2], pk.S3Canonical},
+	}
+	for _, c := range commitments {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		var err error
+		if *c.digest, err = kzg.Commit(c.p, vk.KZGSRS, opt.NbTasks); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return pk, vk, nil
+
+}
+
+// SetupTo behaves like SetupContext, but instead of building the keys in memory it writes the
+// ProvingKey to pkw and the VerifyingKey to vkw, as their WriteRawTo methods do.
+//
+// The polynomials are computed one at a time: each one is committed to, written, and dropped
+// before the next one is computed. Only the permutation is fully held in memory.
+func SetupTo(ctx context.Context, spr *cs.SparseR1CS, srs *kzg.SRS, pkw, vkw io.Writer, opt backend.SetupConfig) error {
+	pk, err := initKeys(spr, srs, opt)
+	if err != nil {
+		return err
+	}
+	vk := pk.Vk
+
+	buildPermutation(spr, pk)
+	polynomials := setupPolynomials(spr, pk)
+
+	// digests of the polynomials, in the order of setupPolynomials. LQk is not committed to
+	digests := []*kzg.Digest{&vk.Ql, &vk.Qr, &vk.Qm, &vk.Qo, &vk.Qk, nil, &vk.S[0], &vk.S[1], &vk.S[2]}
+
+	// the proving key is written in a container, section by section in the order of
+	// ProvingKey.writeTo, which encodes the verifying key last
+	_, err = gnarkio.WriteContainer(pkw, header(gnarkio.KindProvingKey), gnarkio.WriterToFunc(func(w io.Writer) (int64, error) {
+		var n int64
+		for i := range pk.Domain {
+			n2, err := pk.Domain[i].WriteTo(w)
+			n += n2
+			if err != nil {
+				return n, err
+			}
+		}
+		enc := curve.NewEncoder(w, curve.RawEncoding())
+		for i, polynomial := range polynomials {
+			if err := ctx.Err(); err != nil {
+				return n + enc.BytesWritten(), err
+			}
+			p := polynomial()
+			if digests[i] != nil {
+				var err error
+				if *digests[i], err = kzg.Commit(p, vk.KZGSRS, opt.NbTasks); err != nil {
+					return n + enc.BytesWritten(), err
+				}
+			}
+			if err := enc.Encode(p); err != nil {
+				return n + enc.BytesWritten(), err
+			}
+		}
+		if err := enc.Encode(pk.Permutation); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+		n += enc.BytesWritten()
+
+		n2, err := vk.writeTo(w, true)
+		return n + n2, err
+	}))
+	if err != nil {
+		return err
+	}
+
+	_, err = vk.WriteRawTo(vkw)
+	return err
+}
+
+// initKeys returns a ProvingKey, and its embedded VerifyingKey, with the domains,
+// the sizes and the KZG SRS set for spr
+func initKeys(spr *cs.SparseR1CS, srs *kzg.SRS, opt backend.SetupConfig) (*ProvingKey, error) {
 	var pk ProvingKey
 	var vk VerifyingKey
+	vk.NoZeroKnowledge = opt.NoZeroKnowledge
 
 	// The verifying key shares data with the proving key
 	pk.Vk = &vk
@@ -102,8 +244,8 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 
 	// h, the quotient polynomial is of degree 3(n+1)+2, so it's in a 3(n+2) dim vector space,
 	// the domain is the next power of 2 superior to 3(n+2). 4*domainNum is enough in all cases
-	// except when n<6.
-	if sizeSystem < 6 {
+	// except when n<6. Without the blinding, h is of degree 3n-4 and 4*domainNum is always enough.
+	if sizeSystem < 6 && !opt.NoZeroKnowledge {
 		pk.Domain[1] = *fft.NewDomain(8 * sizeSystem)
 	} else {
 		pk.Domain[1] = *fft.NewDomain(4 * sizeSystem)
@@ -115,83 +257,82 @@ func Setup(spr *cs.SparseR1CS, srs *kzg.SRS) (*ProvingKey, *VerifyingKey, error)
 	vk.NbPublicVariables = uint64(spr.NbPublicVariables)
 
 	if err := pk.InitKZG(srs); err != nil {
-		return nil, nil, err
-	}
-
-	// public polynomials corresponding to constraints: [ placholders | constraints | assertions ]
-	pk.Ql = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.Qr = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.Qm = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.Qo = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.CQk = make([]fr.Element, pk.Domain[0].Cardinality)
-	pk.LQk = make([]fr.Element, pk.Domain[0].Cardinality)
-
-	for i := 0; i < spr.NbPublicVariables; i++ { // placeholders (-PUB_INPUT_i + qk_i = 0) TODO should return error is size is inconsistant
-		pk.Ql[i].SetOne().Neg(&pk.Ql[i])
-		pk.Qr[i].SetZero()
-		pk.Qm[i].SetZero()
-		pk.Qo[i].SetZero()
-		pk.CQk[i].SetZero()
-		pk.LQk[i].SetZero() // → to be completed by the prover
-	}
-	offset := spr.NbPublicVariables
-	for i := 0; i < nbConstraints; i++ { // constraints
-
-		pk.Ql[offset+i].Set(&spr.Coefficients[spr.Constraints[i].L.CoeffID()])
-		pk.Qr[offset+i].Set(&spr.Coefficients[spr.Constraints[i].R.CoeffID()])
-		pk.Qm[offset+i].Set(&spr.Coefficients[spr.Constraints[i].M[0].CoeffID()]).
-			Mul(&pk.Qm[offset+i], &spr.Coefficients[spr.Constraints[i].M[1].CoeffID()])
-		pk.Qo[offset+i].Set(&spr.Coefficients[spr.Constraints[i].O.CoeffID()])
-		pk.CQk[offset+i].Set(&spr.Coefficients[spr.Constraints[i].K])
-		pk.LQk[offset+i].Set(&spr.Coefficients[spr.Constraints[i].K])
+		return nil, err
 	}
 
-	pk.Domain[0].FFTInverse(pk.Ql, fft.DIF)
-	pk.Domain[0].FFTInverse(pk.Qr, fft.DIF)
-	pk.Domain[0].FFTInverse(pk.Qm, fft.DIF)
-	pk.Domain[0].FFTInverse(pk.Qo, fft.DIF)
-	pk.Domain[0].FFTInverse(pk.CQk, fft.DIF)
-	fft.BitReverse(pk.Ql)
-	fft.BitReverse(pk.Qr)
-	fft.BitReverse(pk.Qm)
-	fft.BitReverse(pk.Qo)
-	fft.BitReverse(pk.CQk)
-
-	// build permutation. Note: at this stage, the permutation takes in account the placeholders
-	buildPermutation(spr, &pk)
+	return &pk, nil
+}
 
-	// set s1, s2, s3
-	ccomputePermutationPolynomials(&pk)
+// setupPolynomials returns functions computing the polynomials Ql, Qr, Qm, Qo, CQk, LQk,
+// S1Canonical, S2Canonical and S3Canonical of pk, in the order in which ProvingKey.writeTo
+// encodes them. Each call allocates its result, so that the polynomials can be computed one
+// at a time. pk.Domain and pk.Permutation must be set.
+func setupPolynomials(spr *cs.SparseR1CS, pk *ProvingKey) []func() []fr.Element {
+	nbElmts := int(pk.Domain[0].Cardinality)
 
-	// Commit to the polynomials to set up the verifying key
-	var err error
-	if vk.Ql, err = kzg.Commit(pk.Ql, vk.KZGSRS); err != nil {
-		return nil, nil, err
-	}
-	if vk.Qr, err = kzg.Commit(pk.Qr, vk.KZGSRS); err != nil {
-		return nil, nil, err
-	}
-	if vk.Qm, err = kzg.Commit(pk.Qm, vk.KZGSRS); err != nil {
-		return nil, nil, err
-	}
-	if vk.Qo, err = kzg.Commit(pk.Qo, vk.KZGSRS); err != nil {
-		return nil, nil, err
-	}
-	if vk.Qk, err = kzg.Commit(pk.CQk, vk.KZGSRS); err != nil {
-		return nil, nil, err
-	}
-	if vk.S[0], err = kzg.Commit(pk.S1Canonical, vk.KZGSRS); err != nil {
-		return nil, nil, err
-	}
-	if vk.S[1], err = kzg.Commit(pk.S2Canonical, vk.KZGSRS); err != nil {
-		return nil, nil, err
+	// selector returns a selector in Lagrange basis: [ placholders | constraints | assertions ]
+	// placeholders are the constraints -PUB_INPUT_i + qk_i = 0
+	selector := func(placeholder fr.Element, set func(res *fr.Element, c *compiled.SparseR1C)) []fr.Element {
+		res := make([]fr.Element, nbElmts)
+		for i := 0; i < spr.NbPublicVariables; i++ {
+			res[i] = placeholder
+		}
+		offset := spr.NbPublicVariables
+		for i := 0; i < len(spr.Constraints); i++ {
+			set(&res[offset+i], &spr.Constraints[i])
+		}
+		return res
 	}
-	if vk.S[2], err = kzg.Commit(pk.S3Canonical, vk.KZGSRS); err != nil {
-		return nil, nil, err
+
+	canonical := func(p []fr.Element) []fr.Element {
+		pk.Domain[0].FFTInverse(p, fft.DIF)
+		fft.BitReverse(p)
+		return p
 	}
 
-	return &pk, &vk, nil
+	// permutation returns the canonical form of s1, s2 or s3, computed from their LDE (Lagrange basis)
+	//
+	// 1	z 	..	z**n-1	|	u	uz	..	u*z**n-1	|	u**2	u**2*z	..	u**2*z**n-1  |
+	//  																					 |
+	//        																				 | Permutation
+	// s11  s12 ..   s1n	   s21 s22 	 ..		s2n		     s31 	s32 	..		s3n		 v
+	// \---------------/       \--------------------/        \------------------------/
+	// 		s1 (LDE)                s2 (LDE)                          s3 (LDE)
+	permutation := func(id int) []fr.Element {
+		evaluationIDSmallDomain := getIDSmallDomain(&pk.Domain[0])
+		res := make([]fr.Element, nbElmts)
+		for i := 0; i < nbElmts; i++ {
+			res[i].Set(&evaluationIDSmallDomain[pk.Permutation[id*nbElmts+i]])
+		}
+		return canonical(res)
+	}
 
+	var zero, minusOne fr.Element
+	minusOne.SetOne().Neg(&minusOne)
+	coeffs := spr.Coefficients
+	setK := func(res *fr.Element, c *compiled.SparseR1C) { res.Set(&coeffs[c.K]) }
+
+	return []func() []fr.Element{
+		func() []fr.Element {
+			return canonical(selector(minusOne, func(res *fr.Element, c *compiled.SparseR1C) { res.Set(&coeffs[c.L.CoeffID()]) }))
+		},
+		func() []fr.Element {
+			return canonical(selector(zero, func(res *fr.Element, c *compiled.SparseR1C) { res.Set(&coeffs[c.R.CoeffID()]) }))
+		},
+		func() []fr.Element {
+			return canonical(selector(zero, func(res *fr.Element, c *compiled.SparseR1C) {
+				res.Mul(&coeffs[c.M[0].CoeffID()], &coeffs[c.M[1].CoeffID()])
+			}))
+		},
+		func() []fr.Element {
+			return canonical(selector(zero, func(res *fr.Element, c *compiled.SparseR1C) { res.Set(&coeffs[c.O.CoeffID()]) }))
+		},
+		func() []fr.Element { return canonical(selector(zero, setK)) },
+		func() []fr.Element { return selector(zero, setK) }, // → to be completed by the prover
+		func() []fr.Element { return permutation(0) },
+		func() []fr.Element { return permutation(1) },
+		func() []fr.Element { return permutation(2) },
+	}
 }
 
 // buildPermutation builds the Permutation associated with a circuit.
@@ -254,41 +395,9 @@ func buildPermutation(spr *cs.SparseR1CS, pk *ProvingKey) {
 	}
 }
 
-// ccomputePermutationPolynomials computes the LDE (Lagrange basis) of the permutations
-// s1, s2, s3.
-//
-// 1	z 	..	z**n-1	|	u	uz	..	u*z**n-1	|	u**2	u**2*z	..	u**2*z**n-1  |
-//  																					 |
-//        																				 | Permutation
-// s11  s12 ..   s1n	   s21 s22 	 ..		s2n		     s31 	s32 	..		s3n		 v
-// \---------------/       \--------------------/        \------------------------/
-// 		s1 (LDE)                s2 (LDE)                          s3 (LDE)
-func ccomputePermutationPolynomials(pk *ProvingKey) {
-
-	nbElmts := int(pk.Domain[0].Cardinality)
-
-	// Lagrange form of ID
-	evaluationIDSmallDomain := getIDSmallDomain(&pk.Domain[0])
-
-	// Lagrange form of S1, S2, S3
-	pk.S1Canonical = make([]fr.Element, nbElmts)
-	pk.S2Canonical = make([]fr.Element, nbElmts)
-	pk.S3Canonical = make([]fr.Element, nbElmts)
-	for i := 0; i < nbElmts; i++ {
-		pk.S1Canonical[i].Set(&evaluationIDSmallDomain[pk.Permutation[i]])
-		pk.S2Canonical[i].Set(&evaluationIDSmallDomain[pk.Permutation[nbElmts+i]])
-		pk.S3Canonical[i].Set(&evaluationIDSmallDomain[pk.Permutation[2*nbElmts+i]])
-	}
-
-	// Canonical form of S1, S2, S3
-	pk.Domain[0].FFTInverse(pk.S1Canonical, fft.DIF)
-	pk.Domain[0].FFTInverse(pk.S2Canonical, fft.DIF)
-	pk.Domain[0].FFTInverse(pk.S3Canonical, fft.DIF)
-	fft.BitReverse(pk.S1Canonical)
-	fft.BitReverse(pk.S2Canonical)
-	fft.BitReverse(pk.S3Canonical)
-
-	// evaluation of permutation on the big domain
+// computePermutationBigDomain evaluates the permutation polynomials s1, s2, s3 on the big
+// domain from their canonical form. They are not serialized with the ProvingKey.
+func computePermutationBigDomain(pk *ProvingKey) {
 	pk.EvaluationPermutationBigDomainBitReversed = make([]fr.Element, 3*pk.Domain[1].Cardinality)
 	copy(pk.EvaluationPermutationBigDomainBitReversed, pk.S1Canonical)
 	copy(pk.EvaluationPermutationBigDomainBitReversed[pk.Domain[1].Cardinality:], pk.S2Canonical)
@@ -296,7 +405,6 @@ func ccomputePermutationPolynomials(pk *ProvingKey) {
 	pk.Domain[1].FFT(pk.EvaluationPermutationBigDomainBitReversed[:pk.Domain[1].Cardinality], fft.DIF, true)
 	pk.Domain[1].FFT(pk.EvaluationPermutationBigDomainBitReversed[pk.Domain[1].Cardinality:2*pk.Domain[1].Cardinality], fft.DIF, true)
 	pk.Domain[1].FFT(pk.EvaluationPermutationBigDomainBitReversed[2*pk.Domain[1].Cardinality:], fft.DIF, true)
-
 }
 
 // getIDSmallDomain returns the Lagrange form of ID on the small domain
@@ -351,3 +459,13 @@ func (vk *VerifyingKey) NbPublicWitness() int {
 func (pk *ProvingKey) VerifyingKey() interface{} {
 	return pk.Vk
 }
+
+// CurveID returns the curveID
+func (pk *ProvingKey) CurveID() ecc.ID {
+	return curve.ID
+}
+
+// CurveID returns the curveID
+func (vk *VerifyingKey) CurveID() ecc.ID {
+	return curve.ID
+}
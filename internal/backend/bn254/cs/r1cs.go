@@ -23,12 +23,14 @@ import (
 	"io"
 	"math/big"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/backend/circom"
 	"github.com/consensys/gnark/internal/backend/compiled"
 	"github.com/consensys/gnark/internal/backend/ioutils"
 
@@ -65,11 +67,37 @@ func NewR1CS(cs compiled.R1CS, coefficients []big.Int) *R1CS {
 // witness = [publicWires | secretWires] (without the ONE_WIRE !)
 // returns  [publicWires | secretWires | internalWires ]
 func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
+	return cs.solve(witness, a, b, c, make([]fr.Element, nbWires), opt)
+}
 
+// SolveInto is like Solve, but stores the wires in wireValues instead of allocating them.
+// wireValues must hold exactly one element per wire; its previous content, and the one
+// of a, b and c, is discarded.
+func (cs *R1CS) SolveInto(witness, a, b, c, wireValues []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
 	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
-	solution, err := newSolution(nbWires, opt.HintFunctions, cs.Coefficients)
+	if len(wireValues) != nbWires {
+		return wireValues, fmt.Errorf("invalid wire values size, got %d, expected %d", len(wireValues), nbWires)
+	}
+	for i := range wireValues {
+		wireValues[i] = fr.Element{}
+	}
+	for i := range a {
+		a[i] = fr.Element{}
+	}
+	for i := range b {
+		b[i] = fr.Element{}
+	}
+	for i := range c {
+		c[i] = fr.Element{}
+	}
+	return cs.solve(witness, a, b, c, wireValues, opt)
+}
+
+func (cs *R1CS) solve(witness, a, b, c, wireValues []fr.Element, opt backend.ProverConfig) ([]fr.Element, error) {
+	solution, err := newSolution(wireValues, opt.HintFunctions, cs.Coefficients)
 	if err != nil {
-		return make([]fr.Element, nbWires), err
+		return wireValues, err
 	}
 
 	if len(witness) != int(cs.NbPublicVariables-1+cs.NbSecretVariables) { // - 1 for ONE_WIRE
@@ -96,7 +124,7 @@ func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverConfig) (
 	// (or sooner, if a constraint is not satisfied)
 	defer solution.printLogs(opt.LoggerOut, cs.Logs)
 
-	if err := cs.parallelSolve(a, b, c, &solution); err != nil {
+	if err := cs.parallelSolve(a, b, c, &solution, opt.NbTasks); err != nil {
 		return solution.values, err
 	}
 
@@ -108,7 +136,7 @@ func (cs *R1CS) Solve(witness, a, b, c []fr.Element, opt backend.ProverConfig) (
 	return solution.values, nil
 }
 
-func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
+func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution, nbTasks int) error {
 	// minWorkPerCPU is the minimum target number of constraint a task should hold
 	// in other words, if a level has less than minWorkPerCPU, it will not be parallelized and executed
 	// sequentially without sync.
@@ -122,14 +150,18 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 	// then we check that the constraint is valid
 	// if a[i] * b[i] != c[i]; it means the constraint is not satisfied
 
+	if nbTasks <= 0 {
+		nbTasks = runtime.NumCPU()
+	}
+
 	var wg sync.WaitGroup
-	chTasks := make(chan []int, runtime.NumCPU())
-	chError := make(chan error, runtime.NumCPU())
+	chTasks := make(chan []int, nbTasks)
+	chError := make(chan error, nbTasks)
 
 	// start a worker pool
 	// each worker wait on chTasks
 	// a task is a slice of constraint indexes to be solved
-	for i := 0; i < runtime.NumCPU(); i++ {
+	for i := 0; i < nbTasks; i++ {
 		go func() {
 			for t := range chTasks {
 				for _, i := range t {
@@ -183,7 +215,7 @@ func (cs *R1CS) parallelSolve(a, b, c []fr.Element, solution *solution) error {
 
 		// number of tasks for this level is set to num cpus
 		// but if we don't have enough work for all our CPUS, it can be lower.
-		nbTasks := runtime.NumCPU()
+		nbTasks := nbTasks
 		maxTasks := int(math.Ceil(maxCPU))
 		if nbTasks > maxTasks {
 			nbTasks = maxTasks
@@ -450,6 +482,58 @@ func (cs *R1CS) FrSize() int {
 	return fr.Limbs * 8
 }
 
+// WriteCircomR1CS encodes R1CS into provided io.Writer using circom's .r1cs binary format
+//
+// gnark and circom wires are numbered the same way: the constant one wire, the public
+// inputs, the secret inputs and the internal wires. All public inputs are declared as
+// circom public inputs (no outputs) and the label of a wire is its id; see WriteCircomSymbols
+// for the label names.
+func (cs *R1CS) WriteCircomR1CS(w io.Writer) (int64, error) {
+	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
+	header := circom.Header{
+		FieldSize: fr.Limbs * 8,
+		NbWires:   uint32(nbWires),
+		NbPubIn:   uint32(cs.NbPublicVariables - 1),
+		NbPrvIn:   uint32(cs.NbSecretVariables),
+		NbLabels:  uint64(nbWires),
+	}
+	header.Prime.Set(fr.Modulus())
+
+	// circom expects at most one term per wire in a linear combination
+	toLinearCombination := func(l compiled.LinearExpression) circom.LinearCombination {
+		coeffs := make(map[int]fr.Element, len(l))
+		for _, t := range l {
+			c := coeffs[t.WireID()]
+			c.Add(&c, &cs.Coefficients[t.CoeffID()])
+			coeffs[t.WireID()] = c
+		}
+		r := make(circom.LinearCombination, 0, len(coeffs))
+		for wID, c := range coeffs {
+			if c.IsZero() {
+				continue
+			}
+			r = append(r, circom.Term{Wire: uint32(wID)})
+			c.ToBigIntRegular(&r[len(r)-1].Coeff)
+		}
+		sort.Slice(r, func(i, j int) bool { return r[i].Wire < r[j].Wire })
+		return r
+	}
+
+	constraints := make([]circom.Constraint, len(cs.Constraints))
+	for i, r1c := range cs.Constraints {
+		constraints[i][0] = toLinearCombination(r1c.L.LinExp)
+		constraints[i][1] = toLinearCombination(r1c.R.LinExp)
+		constraints[i][2] = toLinearCombination(r1c.O.LinExp)
+	}
+
+	wireToLabel := make([]uint64, nbWires)
+	for i := 0; i < nbWires; i++ {
+		wireToLabel[i] = uint64(i)
+	}
+
+	return circom.WriteR1CS(w, header, constraints, wireToLabel)
+}
+
 // WriteTo encodes R1CS into provided io.Writer using cbor
 func (cs *R1CS) WriteTo(w io.Writer) (int64, error) {
 	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
@@ -106,10 +106,32 @@ func BenchmarkProver(b *testing.B) {
 
 	b.ResetTimer()
 	b.Run("prover", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			_, _ = bn254groth16.Prove(r1cs.(*cs.R1CS), &pk, fullWitness, backend.ProverConfig{})
 		}
 	})
+
+	b.Run("prover (reused buffers)", func(b *testing.B) {
+		prover := bn254groth16.NewProver(r1cs.(*cs.R1CS), &pk, backend.ProverConfig{})
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = prover.Prove(fullWitness)
+		}
+	})
+
+	b.Run("prover (precomputed tables)", func(b *testing.B) {
+		pkPrecomputed := pk
+		if err := pkPrecomputed.Precompute(14); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, _ = bn254groth16.Prove(r1cs.(*cs.R1CS), &pkPrecomputed, fullWitness, backend.ProverConfig{})
+		}
+	})
 }
 
 func BenchmarkVerifier(b *testing.B) {
@@ -166,6 +166,77 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	properties.TestingRun(t, gopter.ConsoleReporter(false))
 }
 
+func TestSnarkJSSerialization(t *testing.T) {
+	parameters := gopter.DefaultTestParameters()
+	parameters.MinSuccessfulTests = 10
+
+	properties := gopter.NewProperties(parameters)
+
+	properties.Property("Proof -> snarkjs -> Proof should stay constant", prop.ForAll(
+		func(ar, krs curve.G1Affine, bs curve.G2Affine) bool {
+			var proof, reconstructed Proof
+			proof.Ar = ar
+			proof.Krs = krs
+			proof.Bs = bs
+
+			data, err := proof.MarshalSnarkJS()
+			if err != nil {
+				t.Log(err)
+				return false
+			}
+			if err := reconstructed.UnmarshalSnarkJS(data); err != nil {
+				t.Log(err)
+				return false
+			}
+			return reflect.DeepEqual(&proof, &reconstructed)
+		},
+		GenG1(),
+		GenG1(),
+		GenG2(),
+	))
+
+	properties.Property("VerifyingKey -> snarkjs -> VerifyingKey should stay constant", prop.ForAll(
+		func(p1 curve.G1Affine, p2 curve.G2Affine) bool {
+			var vk, reconstructed VerifyingKey
+
+			// G1.Beta and G1.Delta are not part of snarkjs's format
+			vk.G1.Alpha = p1
+			vk.G2.Gamma = p2
+			vk.G2.Beta = p2
+			vk.G2.Delta = p2
+
+			var err error
+			vk.e, err = curve.Pair([]curve.G1Affine{vk.G1.Alpha}, []curve.G2Affine{vk.G2.Beta})
+			if err != nil {
+				t.Fatal(err)
+				return false
+			}
+			vk.G2.deltaNeg.Neg(&vk.G2.Delta)
+			vk.G2.gammaNeg.Neg(&vk.G2.Gamma)
+
+			vk.G1.K = make([]curve.G1Affine, 3)
+			for i := 0; i < len(vk.G1.K); i++ {
+				vk.G1.K[i] = p1
+			}
+
+			data, err := vk.MarshalSnarkJS()
+			if err != nil {
+				t.Log(err)
+				return false
+			}
+			if err := reconstructed.UnmarshalSnarkJS(data); err != nil {
+				t.Log(err)
+				return false
+			}
+			return reflect.DeepEqual(&vk, &reconstructed)
+		},
+		GenG1(),
+		GenG2(),
+	))
+
+	properties.TestingRun(t, gopter.ConsoleReporter(false))
+}
+
 func TestProvingKeySerialization(t *testing.T) {
 	parameters := gopter.DefaultTestParameters()
 	parameters.MinSuccessfulTests = 10
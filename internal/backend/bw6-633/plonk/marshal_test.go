@@ -23,6 +23,7 @@ import (
 
 	"bytes"
 	"github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	"io"
 	"reflect"
 	"testing"
 )
@@ -66,6 +67,13 @@ func TestProvingKeySerialization(t *testing.T) {
 	pk.Permutation[0] = -12
 	pk.Permutation[len(pk.Permutation)-1] = 8888
 
+	pk.S1Canonical = make([]fr.Element, pk.Domain[0].Cardinality)
+	pk.S2Canonical = make([]fr.Element, pk.Domain[0].Cardinality)
+	pk.S3Canonical = make([]fr.Element, pk.Domain[0].Cardinality)
+	pk.S1Canonical[0].SetUint64(3)
+	pk.S3Canonical[1].SetOne()
+	computePermutationBigDomain(&pk)
+
 	var buf bytes.Buffer
 	written, err := pk.WriteTo(&buf)
 	if err != nil {
@@ -86,6 +94,8 @@ func TestProvingKeySerialization(t *testing.T) {
 	if written != read {
 		t.Fatal("bytes written / read don't match")
 	}
+
+	assertRawRoundTrip(t, &pk, func() serializable { return new(ProvingKey) })
 }
 
 func TestVerifyingKeySerialization(t *testing.T) {
@@ -103,6 +113,7 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	vk.Qm = g1gen
 	vk.Qo = g1gen
 	vk.Qk = g1gen
+	vk.NoZeroKnowledge = true
 
 	var buf bytes.Buffer
 	written, err := vk.WriteTo(&buf)
@@ -124,4 +135,83 @@ func TestVerifyingKeySerialization(t *testing.T) {
 	if written != read {
 		t.Fatal("bytes written / read don't match")
 	}
+
+	assertRawRoundTrip(t, &vk, func() serializable { return new(VerifyingKey) })
+}
+
+func TestProofSerialization(t *testing.T) {
+	_, _, g1gen, _ := curve.Generators()
+	var proof Proof
+	proof.LRO = [3]curve.G1Affine{g1gen, g1gen, g1gen}
+	proof.Z = g1gen
+	proof.H = [3]curve.G1Affine{g1gen, g1gen, g1gen}
+	proof.BatchedProof.H = g1gen
+	proof.BatchedProof.ClaimedValues = make([]fr.Element, 7)
+	for i := range proof.BatchedProof.ClaimedValues {
+		proof.BatchedProof.ClaimedValues[i].SetUint64(uint64(i))
+	}
+	proof.ZShiftedOpening.H = g1gen
+	proof.ZShiftedOpening.ClaimedValue.SetUint64(42)
+
+	var buf bytes.Buffer
+	written, err := proof.WriteTo(&buf)
+	if err != nil {
+		t.Fatal("coudln't serialize", err)
+	}
+	var reconstructed Proof
+	read, err := reconstructed.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal("coudln't deserialize", err)
+	}
+	if !reflect.DeepEqual(&proof, &reconstructed) {
+		t.Fatal("reconstructed object don't match original")
+	}
+	if written != read {
+		t.Fatal("bytes written / read don't match")
+	}
+
+	assertRawRoundTrip(t, &proof, func() serializable { return new(Proof) })
+}
+
+type serializable interface {
+	io.WriterTo
+	io.ReaderFrom
+	WriteRawTo(w io.Writer) (int64, error)
+	UnsafeReadFrom(r io.Reader) (int64, error)
+}
+
+// assertRawRoundTrip checks that o encoded with WriteRawTo decodes to o with ReadFrom and
+// UnsafeReadFrom, and that the raw encoding is larger than the compressed one
+func assertRawRoundTrip(t *testing.T, o serializable, newObject func() serializable) {
+	var compressed, raw bytes.Buffer
+	if _, err := o.WriteTo(&compressed); err != nil {
+		t.Fatal("coudln't serialize", err)
+	}
+	written, err := o.WriteRawTo(&raw)
+	if err != nil {
+		t.Fatal("coudln't serialize", err)
+	}
+	if raw.Len() <= compressed.Len() {
+		t.Fatal("raw encoding should be larger than the compressed one")
+	}
+
+	for _, unsafe := range []bool{false, true} {
+		reconstructed := newObject()
+		r := bytes.NewReader(raw.Bytes())
+		var read int64
+		if unsafe {
+			read, err = reconstructed.UnsafeReadFrom(r)
+		} else {
+			read, err = reconstructed.ReadFrom(r)
+		}
+		if err != nil {
+			t.Fatal("coudln't deserialize", err)
+		}
+		if !reflect.DeepEqual(o, reconstructed) {
+			t.Fatal("reconstructed object don't match original")
+		}
+		if written != read {
+			t.Fatal("bytes written / read don't match")
+		}
+	}
 }
@@ -44,12 +44,13 @@ type solution struct {
 	mHintsFunctions      map[hint.ID]hint.Function
 }
 
-func newSolution(nbWires int, hintFunctions []hint.Function, coefficients []fr.Element) (solution, error) {
+// newSolution returns a solution storing the wire values in values
+func newSolution(values []fr.Element, hintFunctions []hint.Function, coefficients []fr.Element) (solution, error) {
 
 	s := solution{
-		values:          make([]fr.Element, nbWires),
+		values:          values,
 		coefficients:    coefficients,
-		solved:          make([]bool, nbWires),
+		solved:          make([]bool, len(values)),
 		mHintsFunctions: make(map[hint.ID]hint.Function, len(hintFunctions)),
 	}
 
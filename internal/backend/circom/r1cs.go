@@ -0,0 +1,371 @@
+// Package circom encodes constraint systems in the binary formats used by circom
+// and snarkjs.
+//
+// See https://github.com/iden3/r1csfile/blob/master/doc/r1cs_bin_format.md
+package circom
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+const (
+	r1csMagic   = "r1cs"
+	r1csVersion = 1
+
+	sectionHeader      = 1
+	sectionConstraints = 2
+	sectionWire2Label  = 3
+)
+
+// Header is the content of the header section of a .r1cs file
+type Header struct {
+	FieldSize     uint32  // size in bytes of a field element, multiple of 8
+	Prime         big.Int // modulus of the field
+	NbWires       uint32  // including the constant one wire
+	NbPubOut      uint32
+	NbPubIn       uint32
+	NbPrvIn       uint32
+	NbLabels      uint64
+	NbConstraints uint32
+}
+
+// Term is a coefficient applied to a wire. Coeff must be reduced modulo the prime.
+type Term struct {
+	Wire  uint32
+	Coeff big.Int
+}
+
+// LinearCombination is a sum of terms, wire ids must be unique.
+type LinearCombination []Term
+
+// Constraint is a rank-1 constraint A⋅B = C
+type Constraint [3]LinearCombination
+
+// WriteR1CS writes a .r1cs file with the header, constraints and wire to label
+// sections. NbConstraints in the header is set from the provided constraints.
+func WriteR1CS(w io.Writer, header Header, constraints []Constraint, wireToLabel []uint64) (int64, error) {
+	if header.FieldSize == 0 || header.FieldSize%8 != 0 {
+		return 0, errors.New("field size must be a positive multiple of 8")
+	}
+	if len(wireToLabel) != int(header.NbWires) {
+		return 0, errors.New("wire to label map must have one entry per wire")
+	}
+	header.NbConstraints = uint32(len(constraints))
+	n8 := uint64(header.FieldSize)
+
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	bw := bufio.NewWriter(&_w)
+	e := encoder{w: bw, fieldSize: int(n8)}
+
+	e.writeBytes([]byte(r1csMagic))
+	e.writeUint32(r1csVersion)
+	e.writeUint32(3)
+
+	// header
+	e.writeUint32(sectionHeader)
+	e.writeUint64(4 + n8 + 4*4 + 8 + 4)
+	e.writeUint32(header.FieldSize)
+	e.writeElement(&header.Prime)
+	e.writeUint32(header.NbWires)
+	e.writeUint32(header.NbPubOut)
+	e.writeUint32(header.NbPubIn)
+	e.writeUint32(header.NbPrvIn)
+	e.writeUint64(header.NbLabels)
+	e.writeUint32(header.NbConstraints)
+
+	// constraints; the size of the section is known before encoding it
+	var size uint64
+	for i := 0; i < len(constraints); i++ {
+		for j := 0; j < 3; j++ {
+			size += 4 + uint64(len(constraints[i][j]))*(4+n8)
+		}
+	}
+	e.writeUint32(sectionConstraints)
+	e.writeUint64(size)
+	for i := 0; i < len(constraints); i++ {
+		for j := 0; j < 3; j++ {
+			l := constraints[i][j]
+			e.writeUint32(uint32(len(l)))
+			for k := 0; k < len(l); k++ {
+				if l[k].Wire >= header.NbWires {
+					return _w.N, errors.New("wire id out of range")
+				}
+				e.writeUint32(l[k].Wire)
+				e.writeElement(&l[k].Coeff)
+			}
+		}
+	}
+
+	// wire to label
+	e.writeUint32(sectionWire2Label)
+	e.writeUint64(8 * uint64(len(wireToLabel)))
+	for i := 0; i < len(wireToLabel); i++ {
+		e.writeUint64(wireToLabel[i])
+	}
+
+	if e.err != nil {
+		return _w.N, e.err
+	}
+	err := bw.Flush()
+	return _w.N, err
+}
+
+// ReadR1CS reads a .r1cs file. Only the header, constraints and wire to label
+// sections are supported; files using circom's custom gates are rejected.
+func ReadR1CS(r io.Reader) (header Header, constraints []Constraint, wireToLabel []uint64, err error) {
+	sections, err := readSections(r, r1csMagic, r1csVersion)
+	if err != nil {
+		return
+	}
+	for t := range sections {
+		if t != sectionHeader && t != sectionConstraints && t != sectionWire2Label {
+			err = fmt.Errorf("unsupported section type %d", t)
+			return
+		}
+	}
+
+	// header
+	d, ok := sections[sectionHeader]
+	if !ok {
+		err = errors.New("missing header section")
+		return
+	}
+	header.FieldSize = d.readUint32()
+	if header.FieldSize == 0 || header.FieldSize%8 != 0 {
+		err = errors.New("field size must be a positive multiple of 8")
+		return
+	}
+	d.fieldSize = int(header.FieldSize)
+	d.readElement(&header.Prime)
+	header.NbWires = d.readUint32()
+	header.NbPubOut = d.readUint32()
+	header.NbPubIn = d.readUint32()
+	header.NbPrvIn = d.readUint32()
+	header.NbLabels = d.readUint64()
+	header.NbConstraints = d.readUint32()
+	if err = d.close(); err != nil {
+		return
+	}
+	if uint64(header.NbPubOut)+uint64(header.NbPubIn)+uint64(header.NbPrvIn) >= uint64(header.NbWires) {
+		err = errors.New("invalid number of wires")
+		return
+	}
+
+	// constraints
+	if d, ok = sections[sectionConstraints]; !ok {
+		err = errors.New("missing constraints section")
+		return
+	}
+	d.fieldSize = int(header.FieldSize)
+	if uint64(header.NbConstraints) > uint64(len(d.buf))/12 {
+		err = errors.New("invalid number of constraints")
+		return
+	}
+	constraints = make([]Constraint, 0, header.NbConstraints)
+	for i := 0; i < int(header.NbConstraints) && d.err == nil; i++ {
+		var c Constraint
+		for j := 0; j < 3; j++ {
+			nbTerms := d.readUint32()
+			if uint64(nbTerms) > uint64(len(d.buf))/uint64(4+header.FieldSize) {
+				err = errors.New("invalid number of terms")
+				return
+			}
+			c[j] = make(LinearCombination, nbTerms)
+			for k := 0; k < int(nbTerms); k++ {
+				c[j][k].Wire = d.readUint32()
+				d.readElement(&c[j][k].Coeff)
+				if d.err == nil && c[j][k].Wire >= header.NbWires {
+					err = errors.New("wire id out of range")
+					return
+				}
+				if d.err == nil && c[j][k].Coeff.Cmp(&header.Prime) >= 0 {
+					err = errors.New("coefficient is not reduced")
+					return
+				}
+			}
+		}
+		constraints = append(constraints, c)
+	}
+	if err = d.close(); err != nil {
+		return
+	}
+
+	// wire to label
+	if d, ok = sections[sectionWire2Label]; !ok {
+		err = errors.New("missing wire to label section")
+		return
+	}
+	if uint64(len(d.buf)) != 8*uint64(header.NbWires) {
+		err = errors.New("invalid wire to label section size")
+		return
+	}
+	wireToLabel = make([]uint64, header.NbWires)
+	for i := 0; i < len(wireToLabel); i++ {
+		wireToLabel[i] = d.readUint64()
+	}
+	err = d.close()
+	return
+}
+
+// WriteSymbols writes a .sym file mapping the labels to their names, one
+// "labelId,wireId,componentId,name" entry per line. The label of wire i is i and
+// all the wires belong to the main component. The constant one wire (label 0) is
+// not listed, as in circom's output.
+func WriteSymbols(w io.Writer, names []string) error {
+	bw := bufio.NewWriter(w)
+	for i := 1; i < len(names); i++ {
+		if _, err := fmt.Fprintf(bw, "%d,%d,0,%s\n", i, i, names[i]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readSections checks the magic number and version of a circom binary file and
+// returns the content of its sections, indexed by type
+func readSections(r io.Reader, magic string, version uint32) (map[uint32]*decoder, error) {
+	var b [12]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return nil, err
+	}
+	if string(b[:4]) != magic {
+		return nil, fmt.Errorf("invalid magic number, expected %q", magic)
+	}
+	if v := binary.LittleEndian.Uint32(b[4:]); v != version {
+		return nil, fmt.Errorf("unsupported version %d", v)
+	}
+	nbSections := binary.LittleEndian.Uint32(b[8:])
+
+	sections := make(map[uint32]*decoder)
+	for i := uint32(0); i < nbSections; i++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		t := binary.LittleEndian.Uint32(b[:4])
+		size := binary.LittleEndian.Uint64(b[4:])
+		if _, ok := sections[t]; ok {
+			return nil, fmt.Errorf("duplicate section type %d", t)
+		}
+		// don't trust size to allocate the buffer
+		buf, err := io.ReadAll(io.LimitReader(r, int64(size)))
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(buf)) != size {
+			return nil, io.ErrUnexpectedEOF
+		}
+		sections[t] = &decoder{buf: buf}
+	}
+	return sections, nil
+}
+
+// decoder reads little endian values from a section and keeps the first error encountered
+type decoder struct {
+	buf       []byte
+	fieldSize int
+	err       error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	r := d.buf[:n]
+	d.buf = d.buf[n:]
+	return r
+}
+
+func (d *decoder) readUint32() uint32 {
+	if b := d.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) readUint64() uint64 {
+	if b := d.next(8); b != nil {
+		return binary.LittleEndian.Uint64(b)
+	}
+	return 0
+}
+
+// readElement reads a little endian field element on fieldSize bytes
+func (d *decoder) readElement(v *big.Int) {
+	b := d.next(d.fieldSize)
+	if b == nil {
+		return
+	}
+	be := make([]byte, len(b))
+	for i := 0; i < len(b); i++ {
+		be[len(b)-1-i] = b[i]
+	}
+	v.SetBytes(be)
+}
+
+// close returns the first error encountered, or an error if the section was not fully read
+func (d *decoder) close() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.buf) != 0 {
+		return errors.New("invalid section size")
+	}
+	return nil
+}
+
+// encoder writes little endian values and keeps the first error encountered
+type encoder struct {
+	w         io.Writer
+	fieldSize int
+	buf       []byte
+	err       error
+}
+
+func (e *encoder) writeBytes(b []byte) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(b)
+}
+
+func (e *encoder) writeUint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	e.writeBytes(b[:])
+}
+
+func (e *encoder) writeUint64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	e.writeBytes(b[:])
+}
+
+// writeElement writes v on fieldSize bytes, little endian
+func (e *encoder) writeElement(v *big.Int) {
+	if e.err != nil {
+		return
+	}
+	if v.Sign() < 0 || (v.BitLen()+7)/8 > e.fieldSize {
+		e.err = errors.New("field element out of range")
+		return
+	}
+	if len(e.buf) != e.fieldSize {
+		e.buf = make([]byte, e.fieldSize)
+	}
+	v.FillBytes(e.buf)
+	for i, j := 0, len(e.buf)-1; i < j; i, j = i+1, j-1 {
+		e.buf[i], e.buf[j] = e.buf[j], e.buf[i]
+	}
+	e.writeBytes(e.buf)
+}
@@ -0,0 +1,154 @@
+package circom_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/internal/backend/circom"
+	"github.com/stretchr/testify/require"
+)
+
+type circuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *circuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(api.Add(x3, c.X, 5), c.Y)
+	return nil
+}
+
+func TestWriteCircomR1CS(t *testing.T) {
+	assert := require.New(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		ccs, err := frontend.Compile(curve, backend.GROTH16, &circuit{}, frontend.WithBuilder(r1cs.NewBuilder))
+		assert.NoError(err)
+
+		var buf bytes.Buffer
+		n, err := ccs.WriteCircomR1CS(&buf)
+		assert.NoError(err)
+		assert.Equal(int64(buf.Len()), n)
+
+		b := buf.Bytes()
+		assert.Equal("r1cs", string(b[:4]))
+		assert.Equal(uint32(1), binary.LittleEndian.Uint32(b[4:]))
+		assert.Equal(uint32(3), binary.LittleEndian.Uint32(b[8:]))
+
+		// header section
+		assert.Equal(uint32(1), binary.LittleEndian.Uint32(b[12:]))
+		n8 := int(binary.LittleEndian.Uint32(b[24:]))
+		assert.Equal(ccs.FrSize(), n8)
+		prime := make([]byte, n8)
+		for i := 0; i < n8; i++ {
+			prime[n8-1-i] = b[28+i]
+		}
+		assert.Equal(0, new(big.Int).SetBytes(prime).Cmp(curve.Info().Fr.Modulus()))
+
+		h := b[28+n8:]
+		internal, secret, public := ccs.GetNbVariables()
+		nWires := binary.LittleEndian.Uint32(h[0:])
+		assert.Equal(uint32(internal+secret+public), nWires, "nWires")
+		assert.Equal(uint32(0), binary.LittleEndian.Uint32(h[4:]), "nPubOut")
+		assert.Equal(uint32(public-1), binary.LittleEndian.Uint32(h[8:]), "nPubIn")
+		assert.Equal(uint32(secret), binary.LittleEndian.Uint32(h[12:]), "nPrvIn")
+		assert.Equal(uint64(nWires), binary.LittleEndian.Uint64(h[16:]), "nLabels")
+		assert.Equal(uint32(ccs.GetNbConstraints()), binary.LittleEndian.Uint32(h[24:]), "mConstraints")
+
+		// constraints section
+		c := h[28:]
+		assert.Equal(uint32(2), binary.LittleEndian.Uint32(c))
+		size := binary.LittleEndian.Uint64(c[4:])
+
+		// wire to label section
+		l := c[12+size:]
+		assert.Equal(uint32(3), binary.LittleEndian.Uint32(l))
+		assert.Equal(uint64(8*(internal+secret+public)), binary.LittleEndian.Uint64(l[4:]))
+		assert.Equal(12+8*(internal+secret+public), len(l))
+		for i := 0; i < internal+secret+public; i++ {
+			assert.Equal(uint64(i), binary.LittleEndian.Uint64(l[12+8*i:]))
+		}
+
+		// symbols
+		var sym strings.Builder
+		assert.NoError(ccs.WriteCircomSymbols(&sym))
+		lines := strings.Split(strings.TrimSpace(sym.String()), "\n")
+		assert.Equal(internal+secret+public-1, len(lines))
+		assert.Equal("1,1,0,main.Y", lines[0])
+		assert.Equal("2,2,0,main.X", lines[1])
+	}
+}
+
+func TestR1CSRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	header := circom.Header{
+		FieldSize: 32,
+		NbWires:   5,
+		NbPubOut:  1,
+		NbPubIn:   1,
+		NbPrvIn:   1,
+		NbLabels:  7,
+	}
+	header.Prime.Set(ecc.BN254.Info().Fr.Modulus())
+	var minusOne big.Int
+	minusOne.Sub(&header.Prime, big.NewInt(1))
+	constraints := []circom.Constraint{
+		{{{Wire: 3, Coeff: *big.NewInt(1)}}, {{Wire: 3, Coeff: *big.NewInt(1)}}, {{Wire: 4, Coeff: *big.NewInt(1)}}},
+		{{{Wire: 4, Coeff: minusOne}}, {{Wire: 3, Coeff: *big.NewInt(1)}}, {{Wire: 1, Coeff: minusOne}, {Wire: 2, Coeff: *big.NewInt(1)}}},
+		{nil, nil, nil},
+	}
+	wireToLabel := []uint64{0, 1, 2, 3, 6}
+
+	var buf bytes.Buffer
+	_, err := circom.WriteR1CS(&buf, header, constraints, wireToLabel)
+	assert.NoError(err)
+
+	header.NbConstraints = uint32(len(constraints))
+	rHeader, rConstraints, rWireToLabel, err := circom.ReadR1CS(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(header, rHeader)
+	assert.Equal(wireToLabel, rWireToLabel)
+	assert.Equal(len(constraints), len(rConstraints))
+	for i := range constraints {
+		for j := 0; j < 3; j++ {
+			assert.Equal(len(constraints[i][j]), len(rConstraints[i][j]))
+			for k := range constraints[i][j] {
+				assert.Equal(constraints[i][j][k].Wire, rConstraints[i][j][k].Wire)
+				assert.Equal(0, constraints[i][j][k].Coeff.Cmp(&rConstraints[i][j][k].Coeff))
+			}
+		}
+	}
+
+	// truncated file
+	_, _, _, err = circom.ReadR1CS(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.Error(err)
+}
+
+func TestWitnessRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	prime := ecc.BLS12_381.Info().Fr.Modulus()
+	values := []big.Int{*big.NewInt(1), *big.NewInt(42), *new(big.Int).Sub(prime, big.NewInt(1))}
+
+	var buf bytes.Buffer
+	_, err := circom.WriteWitness(&buf, 32, prime, values)
+	assert.NoError(err)
+
+	fieldSize, rPrime, rValues, err := circom.ReadWitness(&buf)
+	assert.NoError(err)
+	assert.Equal(uint32(32), fieldSize)
+	assert.Equal(0, prime.Cmp(&rPrime))
+	assert.Equal(len(values), len(rValues))
+	for i := range values {
+		assert.Equal(0, values[i].Cmp(&rValues[i]))
+	}
+}
@@ -0,0 +1,102 @@
+package circom
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark/internal/backend/ioutils"
+)
+
+const (
+	wtnsMagic   = "wtns"
+	wtnsVersion = 2
+
+	sectionWitnessHeader = 1
+	sectionWitnessValues = 2
+)
+
+// WriteWitness writes a .wtns file containing the values of all the wires,
+// starting with the constant one wire.
+func WriteWitness(w io.Writer, fieldSize uint32, prime *big.Int, values []big.Int) (int64, error) {
+	if fieldSize == 0 || fieldSize%8 != 0 {
+		return 0, errors.New("field size must be a positive multiple of 8")
+	}
+	n8 := uint64(fieldSize)
+
+	_w := ioutils.WriterCounter{W: w} // wraps writer to count the bytes written
+	bw := bufio.NewWriter(&_w)
+	e := encoder{w: bw, fieldSize: int(n8)}
+
+	e.writeBytes([]byte(wtnsMagic))
+	e.writeUint32(wtnsVersion)
+	e.writeUint32(2)
+
+	e.writeUint32(sectionWitnessHeader)
+	e.writeUint64(4 + n8 + 4)
+	e.writeUint32(fieldSize)
+	e.writeElement(prime)
+	e.writeUint32(uint32(len(values)))
+
+	e.writeUint32(sectionWitnessValues)
+	e.writeUint64(n8 * uint64(len(values)))
+	for i := 0; i < len(values); i++ {
+		if values[i].Cmp(prime) >= 0 {
+			return _w.N, errors.New("witness value is not reduced")
+		}
+		e.writeElement(&values[i])
+	}
+
+	if e.err != nil {
+		return _w.N, e.err
+	}
+	err := bw.Flush()
+	return _w.N, err
+}
+
+// ReadWitness reads a .wtns file and returns the field size, the prime and the
+// values of all the wires, starting with the constant one wire.
+func ReadWitness(r io.Reader) (fieldSize uint32, prime big.Int, values []big.Int, err error) {
+	sections, err := readSections(r, wtnsMagic, wtnsVersion)
+	if err != nil {
+		return
+	}
+
+	d, ok := sections[sectionWitnessHeader]
+	if !ok {
+		err = errors.New("missing header section")
+		return
+	}
+	fieldSize = d.readUint32()
+	if fieldSize == 0 || fieldSize%8 != 0 {
+		err = errors.New("field size must be a positive multiple of 8")
+		return
+	}
+	d.fieldSize = int(fieldSize)
+	d.readElement(&prime)
+	nbValues := d.readUint32()
+	if err = d.close(); err != nil {
+		return
+	}
+
+	if d, ok = sections[sectionWitnessValues]; !ok {
+		err = errors.New("missing values section")
+		return
+	}
+	if uint64(len(d.buf)) != uint64(nbValues)*uint64(fieldSize) {
+		err = errors.New("invalid values section size")
+		return
+	}
+	d.fieldSize = int(fieldSize)
+	values = make([]big.Int, nbValues)
+	for i := 0; i < len(values); i++ {
+		d.readElement(&values[i])
+		if values[i].Cmp(&prime) >= 0 {
+			err = errors.New("witness value is not reduced")
+			return
+		}
+	}
+	err = d.close()
+	return
+}
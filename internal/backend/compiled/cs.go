@@ -10,6 +10,7 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend/schema"
+	"github.com/consensys/gnark/internal/backend/circom"
 	"github.com/fxamacker/cbor/v2"
 )
 
@@ -204,6 +205,62 @@ func (cs *CS) GetSchema() *schema.Schema { return cs.Schema }
 
 func (cs *CS) GetConstraints() [][]string { panic("not implemented") }
 
+// WriteCircomR1CS panics
+func (cs *CS) WriteCircomR1CS(w io.Writer) (int64, error) { panic("not implemented") }
+
+// WriteCircomSymbols writes the circom .sym file matching the output of WriteCircomR1CS.
+//
+// Public and secret wires are named after the circuit schema, internal wires
+// after their index (v0, v1... or hv0, hv1... when computed by a hint).
+func (cs *CS) WriteCircomSymbols(w io.Writer) error {
+	nbWires := cs.NbPublicVariables + cs.NbSecretVariables + cs.NbInternalVariables
+	names := make([]string, 1, nbWires)
+	names[0] = "one"
+
+	var public, secret []string
+	if cs.Schema != nil {
+		var a int
+		instance := cs.Schema.Instantiate(reflect.TypeOf(a), false)
+		collectHandler := func(visibility schema.Visibility, name string, _ reflect.Value) error {
+			if visibility == schema.Public {
+				public = append(public, name)
+			} else if visibility == schema.Secret {
+				secret = append(secret, name)
+			}
+			return nil
+		}
+		if _, err := schema.Parse(instance, reflect.TypeOf(a), collectHandler); err != nil {
+			return err
+		}
+	}
+	if len(public) != cs.NbPublicVariables-1 || len(secret) != cs.NbSecretVariables {
+		// no schema, or it doesn't match the wires
+		public, secret = nil, nil
+		for i := 1; i < cs.NbPublicVariables; i++ {
+			public = append(public, fmt.Sprintf("p%d", i-1))
+		}
+		for i := 0; i < cs.NbSecretVariables; i++ {
+			secret = append(secret, fmt.Sprintf("s%d", i))
+		}
+	}
+	for _, name := range public {
+		names = append(names, "main."+name)
+	}
+	for _, name := range secret {
+		names = append(names, "main."+name)
+	}
+	offset := cs.NbPublicVariables + cs.NbSecretVariables
+	for i := 0; i < cs.NbInternalVariables; i++ {
+		if _, isHint := cs.MHints[offset+i]; isHint {
+			names = append(names, fmt.Sprintf("main.hv%d", i))
+		} else {
+			names = append(names, fmt.Sprintf("main.v%d", i))
+		}
+	}
+
+	return circom.WriteSymbols(w, names)
+}
+
 // Counter contains measurements of useful statistics between two Tag
 type Counter struct {
 	From, To      string
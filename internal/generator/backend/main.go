@@ -55,6 +55,7 @@ func main() {
 	datas := []templateData{bls12_377, bls12_381, bn254, bw6_761, bls24_315, bw6_633}
 
 	const importCurve = "../imports.go.tmpl"
+	const msm = "msm.go.tmpl"
 
 	var wg sync.WaitGroup
 
@@ -107,7 +108,18 @@ func main() {
 				{File: filepath.Join(groth16Dir, "prove.go"), Templates: []string{"groth16/groth16.prove.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "setup.go"), Templates: []string{"groth16/groth16.setup.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal.go"), Templates: []string{"groth16/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "mapped.go"), Templates: []string{"groth16/groth16.mapped.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "precompute.go"), Templates: []string{"groth16/groth16.precompute.go.tmpl", msm, importCurve}},
+				{File: filepath.Join(groth16Dir, "checkpoint.go"), Templates: []string{"groth16/groth16.checkpoint.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "snarkjs.go"), Templates: []string{"groth16/groth16.snarkjs.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "mpcsetup_phase1.go"), Templates: []string{"groth16/groth16.mpcsetup.phase1.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "mpcsetup_phase2.go"), Templates: []string{"groth16/groth16.mpcsetup.phase2.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "mpcsetup_utils.go"), Templates: []string{"groth16/groth16.mpcsetup.utils.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "mpcsetup_test.go"), Templates: []string{"groth16/tests/groth16.mpcsetup.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "aggregate.go"), Templates: []string{"groth16/groth16.aggregate.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "aggregate_test.go"), Templates: []string{"groth16/tests/groth16.aggregate.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16Dir, "marshal_test.go"), Templates: []string{"groth16/tests/groth16.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16Dir, "precompute_test.go"), Templates: []string{"groth16/tests/groth16.precompute.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "groth16", "./template/zkpschemes/", entries...); err != nil {
 				panic(err) // TODO handle
@@ -123,10 +135,14 @@ func main() {
 			// plonk
 			entries = []bavard.Entry{
 				{File: filepath.Join(plonkDir, "verify.go"), Templates: []string{"plonk/plonk.verify.go.tmpl", importCurve}},
-				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "prove.go"), Templates: []string{"plonk/plonk.prove.go.tmpl", msm, importCurve}},
 				{File: filepath.Join(plonkDir, "setup.go"), Templates: []string{"plonk/plonk.setup.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal.go"), Templates: []string{"plonk/plonk.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "mapped.go"), Templates: []string{"plonk/plonk.mapped.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "checkpoint.go"), Templates: []string{"plonk/plonk.checkpoint.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "srs.go"), Templates: []string{"plonk/plonk.srs.go.tmpl", importCurve}},
 				{File: filepath.Join(plonkDir, "marshal_test.go"), Templates: []string{"plonk/tests/marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkDir, "prove_test.go"), Templates: []string{"plonk/tests/prove.go.tmpl", importCurve}},
 			}
 			if err := bgen.Generate(d, "plonk", "./template/zkpschemes/", entries...); err != nil {
 				panic(err)
@@ -5,11 +5,12 @@ import (
 	"sync"
 )
 
-// Parallelize process in parallel the work function
+// Parallelize process in parallel the work function. If maxCpus is not set, or not
+// positive, runtime.NumCPU() tasks are used.
 func Parallelize(nbIterations int, work func(int, int), maxCpus ...int) {
 
 	nbTasks := runtime.NumCPU()
-	if len(maxCpus) == 1 {
+	if len(maxCpus) == 1 && maxCpus[0] > 0 {
 		nbTasks = maxCpus[0]
 	}
 	nbIterationsPerCpus := nbIterations / nbTasks
@@ -0,0 +1,412 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+// A container wraps the binary encoding of a gnark object (WriteTo) with a header
+// describing it, and a checksum:
+//
+//	magic (4 bytes) | version (uint16) | kind (uint8) | backend (uint16) | curve (uint16) | payload | crc32c (uint32)
+//
+// integers are big-endian. The payload is written in chunks [uint32(len) | data],
+// terminated by an empty chunk, such that it can be streamed and read by decoders which
+// buffer their input. The checksum covers the header and the data of the payload.
+//
+// A reader can then reject an object of the wrong kind, curve or backend, or a corrupted
+// one, before (or instead of) failing to decode it.
+
+// Magic is the first 4 bytes of a container
+const Magic uint32 = 0x676e726b // "gnrk"
+
+// FormatVersion is the version of the payloads written by this version of gnark. It is
+// increased when the binary encoding of an object changes; the payloads of the previous
+// versions are still read.
+//
+//	0: bare encoding, without a container, written by versions of gnark predating
+//	   containers (see ReadContainerOrLegacy)
+//	1: initial version
+//	2: PlonK verifying keys (and the proving keys embedding them) encode NoZeroKnowledge
+//	3: PlonK proving keys encode their verifying key last
+const FormatVersion uint16 = 3
+
+// Kind of the object in a container
+type Kind uint8
+
+const (
+	KindUnknown Kind = iota
+	KindConstraintSystem
+	KindProvingKey
+	KindVerifyingKey
+	KindProof
+	KindWitness
+	KindCheckpoint
+)
+
+// String returns the string representation of a kind of object
+func (k Kind) String() string {
+	switch k {
+	case KindConstraintSystem:
+		return "constraint system"
+	case KindProvingKey:
+		return "proving key"
+	case KindVerifyingKey:
+		return "verifying key"
+	case KindProof:
+		return "proof"
+	case KindWitness:
+		return "witness"
+	case KindCheckpoint:
+		return "checkpoint"
+	default:
+		return "unknown"
+	}
+}
+
+// Header describes the object in a container
+type Header struct {
+	Version uint16
+	Kind    Kind
+	Backend backend.ID // backend.UNKNOWN for backend agnostic objects (witness)
+	Curve   ecc.ID
+}
+
+// headerSize is the size of the encoded header, magic included
+const headerSize = 4 + 2 + 1 + 2 + 2
+
+var (
+	// ErrInvalidMagic is returned when the data doesn't start with Magic: it's not a
+	// container, or it was written by a version of gnark predating containers
+	ErrInvalidMagic = errors.New("not a gnark container (invalid magic number)")
+
+	// ErrChecksumMismatch is returned when the checksum of a container doesn't match its content
+	ErrChecksumMismatch = errors.New("container checksum mismatch: corrupted data")
+)
+
+// VersionError is returned when a container was written with an unsupported format version
+type VersionError struct {
+	Version uint16
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("unsupported container format version %d (supported: 1 to %d)", e.Version, FormatVersion)
+}
+
+// MismatchError is returned when the header of a container doesn't describe the
+// expected object
+type MismatchError struct {
+	Field            string // "kind", "backend" or "curve"
+	Expected, Actual string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("container %s mismatch: expected %s, got %s", e.Field, e.Expected, e.Actual)
+}
+
+// Check returns a *MismatchError if h doesn't describe the expected object. The zero
+// values of the fields of expected match any value.
+func (h Header) Check(expected Header) error {
+	if expected.Kind != KindUnknown && h.Kind != expected.Kind {
+		return &MismatchError{Field: "kind", Expected: expected.Kind.String(), Actual: h.Kind.String()}
+	}
+	if expected.Backend != backend.UNKNOWN && h.Backend != expected.Backend {
+		return &MismatchError{Field: "backend", Expected: expected.Backend.String(), Actual: h.Backend.String()}
+	}
+	if expected.Curve != ecc.UNKNOWN && h.Curve != expected.Curve {
+		return &MismatchError{Field: "curve", Expected: expected.Curve.String(), Actual: h.Curve.String()}
+	}
+	return nil
+}
+
+// WriterToFunc is an adapter to use a function writing the payload of a container as
+// an io.WriterTo
+type WriterToFunc func(w io.Writer) (int64, error)
+
+// WriteTo calls f(w)
+func (f WriterToFunc) WriteTo(w io.Writer) (int64, error) {
+	return f(w)
+}
+
+// WriteContainer writes h, the encoding of o and the checksum to w. h.Version is set to
+// FormatVersion.
+func WriteContainer(w io.Writer, h Header, o io.WriterTo) (int64, error) {
+	h.Version = FormatVersion
+	crc := newChecksum()
+	cw := &countingWriter{w: w}
+
+	if _, err := io.MultiWriter(cw, crc).Write(h.bytes()); err != nil {
+		return cw.n, err
+	}
+	chunks := &chunkWriter{w: cw}
+	if _, err := o.WriteTo(io.MultiWriter(chunks, crc)); err != nil {
+		return cw.n, err
+	}
+	if err := chunks.close(); err != nil {
+		return cw.n, err
+	}
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	_, err := cw.Write(sum[:])
+	return cw.n, err
+}
+
+// ReadContainer reads a container from r. Its header is checked against expected (see
+// Header.Check), then readPayload decodes the object described by the header from the
+// reader it is given, and the checksum is verified.
+//
+// If the checksum doesn't match, ReadContainer returns ErrChecksumMismatch; the object
+// decoded by readPayload must be discarded.
+func ReadContainer(r io.Reader, expected Header, readPayload func(h Header, r io.Reader) (int64, error)) (Header, int64, error) {
+	crc := newChecksum()
+	cr := &countingReader{r: r}
+
+	h, err := readHeader(io.TeeReader(cr, crc), expected)
+	if err != nil {
+		return h, cr.n, err
+	}
+
+	payload := io.TeeReader(&chunkReader{r: cr}, crc)
+	if _, err := readPayload(h, payload); err != nil {
+		return h, cr.n, err
+	}
+	// the decoder may not have consumed the end of the payload
+	if _, err := io.Copy(io.Discard, payload); err != nil {
+		return h, cr.n, err
+	}
+
+	var sum [4]byte
+	if _, err := io.ReadFull(cr, sum[:]); err != nil {
+		return h, cr.n, err
+	}
+	if binary.BigEndian.Uint32(sum[:]) != crc.Sum32() {
+		return h, cr.n, ErrChecksumMismatch
+	}
+	return h, cr.n, nil
+}
+
+// ReadContainerOrLegacy behaves like ReadContainer, except that if r doesn't start with
+// Magic, it holds the bare encoding of an object written by a version of gnark predating
+// containers: readPayload decodes it from r, with expected as header and a Version of 0.
+func ReadContainerOrLegacy(r io.Reader, expected Header, readPayload func(h Header, r io.Reader) (int64, error)) (Header, int64, error) {
+	h, r, err := ReadHeader(r, expected)
+	if err == ErrInvalidMagic {
+		h = expected
+		h.Version = 0
+		n, err := readPayload(h, r)
+		return h, n, err
+	}
+	// other errors are returned by ReadContainer, with the number of bytes read
+	return ReadContainer(r, expected, readPayload)
+}
+
+// ReadHeader reads and checks the header of the container at the start of r, as
+// ReadContainer does, such that the object it describes can be instantiated before
+// being decoded. The returned reader reads the whole container, header included.
+//
+// If r doesn't start with Magic, ReadHeader returns ErrInvalidMagic and a reader of all
+// the data of r.
+func ReadHeader(r io.Reader, expected Header) (Header, io.Reader, error) {
+	var buf bytes.Buffer
+	h, err := readHeader(io.TeeReader(r, &buf), expected)
+	return h, io.MultiReader(&buf, r), err
+}
+
+// readHeader reads a header from r and checks it against expected. The magic number
+// is read first, such that no more data is consumed from r if it isn't a container.
+func readHeader(r io.Reader, expected Header) (Header, error) {
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r, buf[:4]); err != nil {
+		return Header{}, err
+	}
+	if binary.BigEndian.Uint32(buf[:4]) != Magic {
+		return Header{}, ErrInvalidMagic
+	}
+	if _, err := io.ReadFull(r, buf[4:]); err != nil {
+		return Header{}, noEOF(err)
+	}
+	h := Header{
+		Version: binary.BigEndian.Uint16(buf[4:6]),
+		Kind:    Kind(buf[6]),
+		Backend: backend.ID(binary.BigEndian.Uint16(buf[7:9])),
+		Curve:   ecc.ID(binary.BigEndian.Uint16(buf[9:11])),
+	}
+	if h.Version == 0 || h.Version > FormatVersion {
+		return h, &VersionError{Version: h.Version}
+	}
+	if err := h.Check(expected); err != nil {
+		return h, err
+	}
+	if !isImplemented(h.Curve) {
+		return h, fmt.Errorf("container curve %s is not supported", h.Curve)
+	}
+	return h, nil
+}
+
+func (h Header) bytes() []byte {
+	var buf [headerSize]byte
+	binary.BigEndian.PutUint32(buf[:4], Magic)
+	binary.BigEndian.PutUint16(buf[4:6], h.Version)
+	buf[6] = byte(h.Kind)
+	binary.BigEndian.PutUint16(buf[7:9], uint16(h.Backend))
+	binary.BigEndian.PutUint16(buf[9:11], uint16(h.Curve))
+	return buf[:]
+}
+
+func isImplemented(curve ecc.ID) bool {
+	// gnark-crypto implements BW6-633, but doesn't list it in ecc.Implemented
+	if curve == ecc.BW6_633 {
+		return true
+	}
+	for _, c := range ecc.Implemented() {
+		if c == curve {
+			return true
+		}
+	}
+	return false
+}
+
+func newChecksum() hash.Hash32 {
+	return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// chunkSize is the maximum size of the chunks of the payload
+const chunkSize = 1 << 16
+
+// chunkWriter writes the data in chunks [uint32(len) | data]
+type chunkWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if cw.buf == nil {
+			cw.buf = make([]byte, 0, chunkSize)
+		}
+		m := copy(cw.buf[len(cw.buf):cap(cw.buf)], p)
+		cw.buf = cw.buf[:len(cw.buf)+m]
+		p = p[m:]
+		if len(cw.buf) == chunkSize {
+			if err := cw.flush(); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (cw *chunkWriter) flush() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(cw.buf)))
+	if _, err := cw.w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := cw.w.Write(cw.buf)
+	cw.buf = cw.buf[:0]
+	return err
+}
+
+// close flushes the data and writes the terminating empty chunk
+func (cw *chunkWriter) close() error {
+	if err := cw.flush(); err != nil {
+		return err
+	}
+	_, err := cw.w.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// chunkReader reads the data written by a chunkWriter, and returns io.EOF after the
+// terminating empty chunk
+type chunkReader struct {
+	r         io.Reader
+	remaining uint32
+	eof       bool
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	if cr.eof {
+		return 0, io.EOF
+	}
+	if cr.remaining == 0 {
+		var size [4]byte
+		if _, err := io.ReadFull(cr.r, size[:]); err != nil {
+			return 0, noEOF(err)
+		}
+		cr.remaining = binary.BigEndian.Uint32(size[:])
+		if cr.remaining == 0 {
+			cr.eof = true
+			return 0, io.EOF
+		}
+		if cr.remaining > chunkSize {
+			return 0, errors.New("invalid container chunk size")
+		}
+	}
+	if uint32(len(p)) > cr.remaining {
+		p = p[:cr.remaining]
+	}
+	n, err := cr.r.Read(p)
+	cr.remaining -= uint32(n)
+	if err == io.EOF {
+		// the container must end with an empty chunk and the checksum
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
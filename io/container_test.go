@@ -0,0 +1,156 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/stretchr/testify/require"
+)
+
+// blob is a payload which can only be decoded through a buffered reader
+type blob []byte
+
+func (b blob) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+func (b *blob) readFrom(r io.Reader, size int) (int64, error) {
+	*b = make(blob, size)
+	n, err := io.ReadFull(bufio.NewReader(r), *b)
+	return int64(n), err
+}
+
+func TestContainerRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	header := Header{Kind: KindProvingKey, Backend: backend.GROTH16, Curve: ecc.BLS12_381}
+	for _, size := range []int{0, 1, chunkSize, 3*chunkSize + 7} {
+		payload := make(blob, size)
+		for i := range payload {
+			payload[i] = byte(i * 7)
+		}
+
+		var buf bytes.Buffer
+		written, err := WriteContainer(&buf, header, payload)
+		assert.NoError(err)
+		assert.Equal(int64(buf.Len()), written)
+		buf.WriteString("trailing data")
+
+		var decoded blob
+		h, read, err := ReadContainer(&buf, Header{Kind: KindProvingKey, Curve: ecc.BLS12_381}, func(h Header, r io.Reader) (int64, error) {
+			return decoded.readFrom(r, size)
+		})
+		assert.NoError(err)
+		assert.Equal(written, read)
+		assert.Equal(FormatVersion, h.Version)
+		assert.Equal(header.Curve, h.Curve)
+		assert.Equal(header.Backend, h.Backend)
+		assert.True(bytes.Equal(payload, decoded))
+		assert.Equal("trailing data", buf.String(), "the container must not read past its end")
+	}
+}
+
+func TestContainerErrors(t *testing.T) {
+	assert := require.New(t)
+
+	header := Header{Kind: KindProof, Backend: backend.PLONK, Curve: ecc.BN254}
+	payload := blob("proof")
+	var buf bytes.Buffer
+	_, err := WriteContainer(&buf, header, payload)
+	assert.NoError(err)
+	data := buf.Bytes()
+
+	read := func(data []byte, expected Header) error {
+		_, _, err := ReadContainer(bytes.NewReader(data), expected, func(h Header, r io.Reader) (int64, error) {
+			var decoded blob
+			return decoded.readFrom(r, len(payload))
+		})
+		return err
+	}
+	assert.NoError(read(data, header))
+
+	var mismatch *MismatchError
+	for _, expected := range []Header{
+		{Kind: KindVerifyingKey},
+		{Backend: backend.GROTH16},
+		{Curve: ecc.BLS12_377},
+	} {
+		err := read(data, expected)
+		assert.True(errors.As(err, &mismatch), "expected a *MismatchError, got %v", err)
+	}
+	assert.Equal("curve", mismatch.Field)
+	assert.Equal(ecc.BLS12_377.String(), mismatch.Expected)
+	assert.Equal(ecc.BN254.String(), mismatch.Actual)
+
+	// a bare stream
+	assert.ErrorIs(read([]byte("not a container at all"), header), ErrInvalidMagic)
+
+	// a newer format
+	newer := append([]byte(nil), data...)
+	newer[5]++
+	var version *VersionError
+	assert.True(errors.As(read(newer, header), &version))
+	assert.Equal(FormatVersion+1, version.Version)
+
+	// a corrupted payload
+	corrupted := append([]byte(nil), data...)
+	corrupted[headerSize+4] ^= 1
+	assert.ErrorIs(read(corrupted, header), ErrChecksumMismatch)
+
+	// a truncated container
+	assert.ErrorIs(read(data[:len(data)-2], header), io.ErrUnexpectedEOF)
+}
+
+func TestContainerOrLegacy(t *testing.T) {
+	assert := require.New(t)
+
+	header := Header{Kind: KindVerifyingKey, Backend: backend.GROTH16, Curve: ecc.BN254}
+	payload := blob("verifying key")
+	var buf bytes.Buffer
+	written, err := WriteContainer(&buf, header, payload)
+	assert.NoError(err)
+	container := buf.Bytes()
+
+	read := func(data []byte) (Header, int64, blob, error) {
+		var decoded blob
+		h, n, err := ReadContainerOrLegacy(bytes.NewReader(data), header, func(h Header, r io.Reader) (int64, error) {
+			return decoded.readFrom(r, len(payload))
+		})
+		return h, n, decoded, err
+	}
+
+	h, n, decoded, err := read(container)
+	assert.NoError(err)
+	assert.Equal(FormatVersion, h.Version)
+	assert.Equal(written, n)
+	assert.Equal(payload, decoded)
+
+	// the bare encoding, read as version 0 with the expected header
+	h, n, decoded, err = read(payload)
+	assert.NoError(err)
+	assert.Equal(uint16(0), h.Version)
+	assert.Equal(header.Curve, h.Curve)
+	assert.Equal(int64(len(payload)), n)
+	assert.Equal(payload, decoded)
+
+	// a container of another object isn't read as a bare encoding
+	var mismatch *MismatchError
+	_, _, err = ReadContainerOrLegacy(bytes.NewReader(container), Header{Curve: ecc.BLS12_381}, func(h Header, r io.Reader) (int64, error) {
+		return 0, errors.New("unreachable")
+	})
+	assert.True(errors.As(err, &mismatch), "expected a *MismatchError, got %v", err)
+
+	// ReadHeader doesn't consume the container
+	h, r, err := ReadHeader(bytes.NewReader(container), Header{})
+	assert.NoError(err)
+	assert.Equal(header.Kind, h.Kind)
+	all, err := io.ReadAll(r)
+	assert.NoError(err)
+	assert.Equal(container, all)
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 ConsenSys
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package io
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+)
+
+// A mapped object is laid out such that it can be memory-mapped (see MapFile), and its large
+// arrays used in place instead of being decoded:
+//
+//	magic (4 bytes) | version (uint16) | kind (uint8) | backend (uint16) | curve (uint16) | byte order (uint8) | sections
+//
+// the header is the header of a container (with MappedMagic). Each section starts on a multiple
+// of MappedAlignment bytes with a block holding its length (uint64, little-endian), followed by
+// its data. The arrays are written in their in-memory representation: they can only be mapped
+// on machines with the byte order (0: little-endian, 1: big-endian) of the writer.
+
+// MappedMagic is the first 4 bytes of a mapped object
+const MappedMagic uint32 = 0x676e6d6d // "gnmm"
+
+// MappedAlignment is the alignment of the sections of a mapped object
+const MappedAlignment = 64
+
+// ErrByteOrderMismatch is returned when mapping an object written on a machine with a
+// different byte order
+var ErrByteOrderMismatch = errors.New("mapped object was written with a different byte order")
+
+// MappedWriter writes an object in the mapped layout
+type MappedWriter struct {
+	w io.Writer
+	n int64
+}
+
+// NewMappedWriter writes the header h to w, and returns a MappedWriter to write the sections
+// of the object. h.Version is set to FormatVersion.
+func NewMappedWriter(w io.Writer, h Header) (*MappedWriter, error) {
+	h.Version = FormatVersion
+	var buf [headerSize + 1]byte
+	copy(buf[:], h.bytes())
+	binary.BigEndian.PutUint32(buf[:4], MappedMagic)
+	buf[headerSize] = nativeByteOrder()
+
+	mw := &MappedWriter{w: w}
+	return mw, mw.write(buf[:])
+}
+
+// WriteSlice writes a section holding the elements of s, a slice of values without pointers,
+// as they are represented in memory.
+func (mw *MappedWriter) WriteSlice(s interface{}) error {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Slice || hasPointers(v.Type().Elem()) {
+		return fmt.Errorf("can't write %T in a mapped object", s)
+	}
+	size := v.Len() * int(v.Type().Elem().Size())
+
+	var block [MappedAlignment]byte
+	binary.LittleEndian.PutUint64(block[:8], uint64(size))
+	if err := mw.write(block[:mw.padding()]); err != nil {
+		return err
+	}
+	if err := mw.write(block[:]); err != nil {
+		return err
+	}
+	if size == 0 {
+		return nil
+	}
+	return mw.write(unsafe.Slice((*byte)(unsafe.Pointer(v.Pointer())), size))
+}
+
+// BytesWritten returns the number of bytes written so far
+func (mw *MappedWriter) BytesWritten() int64 {
+	return mw.n
+}
+
+// padding returns the number of bytes to write to reach the next section
+func (mw *MappedWriter) padding() int {
+	return int((MappedAlignment - mw.n%MappedAlignment) % MappedAlignment)
+}
+
+func (mw *MappedWriter) write(p []byte) error {
+	n, err := mw.w.Write(p)
+	mw.n += int64(n)
+	return err
+}
+
+// MappedReader reads the sections of a mapped object
+type MappedReader struct {
+	data   []byte
+	offset int
+}
+
+// NewMappedReader reads the header of the mapped object in data, and checks it against
+// expected (see Header.Check). data must be aligned on MappedAlignment bytes, as a memory
+// mapping is.
+func NewMappedReader(data []byte, expected Header) (*MappedReader, Header, error) {
+	if len(data) < headerSize+1 {
+		return nil, Header{}, io.ErrUnexpectedEOF
+	}
+	if binary.BigEndian.Uint32(data[:4]) != MappedMagic {
+		return nil, Header{}, ErrInvalidMagic
+	}
+	h := Header{
+		Version: binary.BigEndian.Uint16(data[4:6]),
+		Kind:    Kind(data[6]),
+		Backend: backend.ID(binary.BigEndian.Uint16(data[7:9])),
+		Curve:   ecc.ID(binary.BigEndian.Uint16(data[9:11])),
+	}
+	if h.Version == 0 || h.Version > FormatVersion {
+		return nil, h, &VersionError{Version: h.Version}
+	}
+	if err := h.Check(expected); err != nil {
+		return nil, h, err
+	}
+	if data[headerSize] != nativeByteOrder() {
+		return nil, h, ErrByteOrderMismatch
+	}
+	return &MappedReader{data: data, offset: headerSize + 1}, h, nil
+}
+
+// ReadSlice sets the slice dst points to, of values without pointers, to the elements of the
+// next section. The slice shares the memory of the mapped object: it must not be modified, nor
+// used once the object is unmapped.
+func (mr *MappedReader) ReadSlice(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice || hasPointers(v.Elem().Type().Elem()) {
+		return fmt.Errorf("can't read %T from a mapped object", dst)
+	}
+	s, elem := v.Elem(), v.Elem().Type().Elem()
+
+	mr.offset += (MappedAlignment - mr.offset%MappedAlignment) % MappedAlignment
+	if mr.offset+MappedAlignment > len(mr.data) {
+		return io.ErrUnexpectedEOF
+	}
+	size := binary.LittleEndian.Uint64(mr.data[mr.offset:])
+	mr.offset += MappedAlignment
+	if size > uint64(len(mr.data)-mr.offset) {
+		return io.ErrUnexpectedEOF
+	}
+	data := mr.data[mr.offset : mr.offset+int(size)]
+	mr.offset += int(size)
+
+	if elem.Size() == 0 || size%uint64(elem.Size()) != 0 {
+		return fmt.Errorf("mapped section of %d bytes doesn't hold %s elements", size, elem)
+	}
+	n := int(size / uint64(elem.Size()))
+	if n == 0 {
+		s.Set(reflect.MakeSlice(s.Type(), 0, 0))
+		return nil
+	}
+	p := unsafe.Pointer(&data[0])
+	if uintptr(p)%uintptr(elem.Align()) != 0 {
+		return errors.New("mapped object is not aligned")
+	}
+	s.Set(reflect.NewAt(reflect.ArrayOf(n, elem), p).Elem().Slice(0, n))
+	return nil
+}
+
+// hasPointers reports whether values of type t hold pointers, which can't be mapped
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	default:
+		return true
+	}
+}
+
+func nativeByteOrder() uint8 {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return 0
+	}
+	return 1
+}
+
+// MappedFile is a file mapped in memory, read-only
+type MappedFile struct {
+	data  []byte
+	unmap func() error
+}
+
+// MapFile maps the file at path in memory. On unix systems, the pages of the file are read
+// when they are accessed, and can be evicted from memory by the operating system; on other
+// systems, the file is read in memory.
+func MapFile(path string) (*MappedFile, error) {
+	return mapFile(path)
+}
+
+// Bytes returns the content of the file; it must not be modified, nor used after Close.
+func (f *MappedFile) Bytes() []byte {
+	return f.data
+}
+
+// Close unmaps the file
+func (f *MappedFile) Close() error {
+	if f.unmap == nil {
+		return nil
+	}
+	err := f.unmap()
+	f.data, f.unmap = nil, nil
+	return err
+}
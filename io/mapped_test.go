@@ -0,0 +1,90 @@
+package io
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type mappedPoint struct {
+	X, Y [4]uint64
+}
+
+func TestMappedRoundTrip(t *testing.T) {
+	assert := require.New(t)
+
+	header := Header{Kind: KindProvingKey, Backend: backend.PLONK, Curve: ecc.BN254}
+	points := make([]mappedPoint, 1000)
+	for i := range points {
+		points[i].X[0], points[i].Y[3] = uint64(i), uint64(3*i)
+	}
+	flags := []bool{true, false, true}
+	meta := []byte("meta")
+
+	var buf bytes.Buffer
+	mw, err := NewMappedWriter(&buf, header)
+	assert.NoError(err)
+	for _, v := range []interface{}{meta, points, []int64{}, flags} {
+		assert.NoError(mw.WriteSlice(v))
+	}
+	assert.Equal(int64(buf.Len()), mw.BytesWritten())
+	assert.Error(mw.WriteSlice([]*int{nil}), "pointers can't be mapped")
+
+	path := filepath.Join(t.TempDir(), "mapped")
+	assert.NoError(os.WriteFile(path, buf.Bytes(), 0600))
+	f, err := MapFile(path)
+	assert.NoError(err)
+	defer f.Close()
+
+	mr, h, err := NewMappedReader(f.Bytes(), Header{Backend: backend.PLONK})
+	assert.NoError(err)
+	assert.Equal(header.Curve, h.Curve)
+	assert.Equal(FormatVersion, h.Version)
+
+	var (
+		rMeta   []byte
+		rPoints []mappedPoint
+		rEmpty  []int64
+		rFlags  []bool
+	)
+	for _, v := range []interface{}{&rMeta, &rPoints, &rEmpty, &rFlags} {
+		assert.NoError(mr.ReadSlice(v))
+	}
+	assert.Equal(meta, rMeta)
+	assert.Equal(points, rPoints)
+	assert.Empty(rEmpty)
+	assert.Equal(flags, rFlags)
+	assert.ErrorIs(mr.ReadSlice(&rFlags), io.ErrUnexpectedEOF)
+
+	// wrong object
+	_, _, err = NewMappedReader(f.Bytes(), Header{Backend: backend.GROTH16})
+	var mismatch *MismatchError
+	assert.True(errors.As(err, &mismatch))
+
+	// truncated object
+	mr, _, err = NewMappedReader(f.Bytes()[:buf.Len()-1], header)
+	assert.NoError(err)
+	assert.NoError(mr.ReadSlice(&rMeta))
+	assert.NoError(mr.ReadSlice(&rPoints))
+	assert.NoError(mr.ReadSlice(&rEmpty))
+	assert.ErrorIs(mr.ReadSlice(&rFlags), io.ErrUnexpectedEOF)
+
+	// section of the wrong type
+	mr, _, err = NewMappedReader(f.Bytes(), header)
+	assert.NoError(err)
+	var wrong []mappedPoint
+	assert.Error(mr.ReadSlice(&wrong))
+
+	_, _, err = NewMappedReader([]byte("not mapped"), header)
+	assert.Error(err)
+	_, _, err = NewMappedReader(append([]byte{0x67, 0x6e, 0x6d, 0x6d}, make([]byte, 16)...), header)
+	var versionErr *VersionError
+	assert.True(errors.As(err, &versionErr))
+}
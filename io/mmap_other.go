@@ -0,0 +1,23 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package io
+
+import (
+	"os"
+	"unsafe"
+)
+
+func mapFile(path string) (*MappedFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// the sections of a mapped object are aligned on MappedAlignment bytes
+	if len(data) > 0 && uintptr(unsafe.Pointer(&data[0]))%MappedAlignment != 0 {
+		aligned := make([]byte, len(data)+MappedAlignment)
+		offset := (MappedAlignment - int(uintptr(unsafe.Pointer(&aligned[0]))%MappedAlignment)) % MappedAlignment
+		data = append(aligned[offset:offset], data...)
+	}
+	return &MappedFile{data: data}, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package twistededwards
+
+import (
+	"math/big"
+)
+
+// The methods below operate on Coord outside of a circuit. They are used to
+// precompute constant points (fixed-base tables, generators...) at circuit
+// compile time and are not optimized for performance.
+
+// SetInfinity sets p to the neutral element (0, 1) and returns it
+func (p *Coord) SetInfinity() *Coord {
+	p.X.SetUint64(0)
+	p.Y.SetUint64(1)
+	return p
+}
+
+// Set sets p to p1 (deep copy) and returns it
+func (p *Coord) Set(p1 *Coord) *Coord {
+	p.X.Set(&p1.X)
+	p.Y.Set(&p1.Y)
+	return p
+}
+
+// Equal returns true if p and p1 are the same point
+func (p *Coord) Equal(p1 *Coord) bool {
+	return p.X.Cmp(&p1.X) == 0 && p.Y.Cmp(&p1.Y) == 0
+}
+
+// Add sets p = p1 + p2 using the complete twisted Edwards addition law and returns p
+func (p *Coord) Add(p1, p2 *Coord, curve EdCurve) *Coord {
+	// https://eprint.iacr.org/2008/013.pdf
+	q := curve.ID.Info().Fr.Modulus()
+
+	var x1y2, y1x2, y1y2, x1x2, dxy, n1, n2, d1, d2 big.Int
+	x1y2.Mul(&p1.X, &p2.Y)
+	y1x2.Mul(&p1.Y, &p2.X)
+	y1y2.Mul(&p1.Y, &p2.Y)
+	x1x2.Mul(&p1.X, &p2.X)
+
+	dxy.Mul(&x1y2, &y1x2).Mul(&dxy, &curve.D).Mod(&dxy, q)
+
+	n1.Add(&x1y2, &y1x2)
+	n2.Mul(&x1x2, &curve.A).Sub(&y1y2, &n2)
+
+	d1.Add(big.NewInt(1), &dxy).ModInverse(&d1, q)
+	d2.Sub(big.NewInt(1), &dxy).Mod(&d2, q).ModInverse(&d2, q)
+
+	p.X.Mul(&n1, &d1).Mod(&p.X, q)
+	p.Y.Mul(&n2, &d2).Mod(&p.Y, q)
+
+	return p
+}
+
+// Double sets p = [2]p1 and returns p
+func (p *Coord) Double(p1 *Coord, curve EdCurve) *Coord {
+	return p.Add(p1, p1, curve)
+}
+
+// Neg sets p = -p1 and returns p
+func (p *Coord) Neg(p1 *Coord, curve EdCurve) *Coord {
+	q := curve.ID.Info().Fr.Modulus()
+	p.X.Neg(&p1.X).Mod(&p.X, q)
+	p.Y.Set(&p1.Y)
+	return p
+}
+
+// ScalarMul sets p = [s]p1 using a left to right double and add and returns p
+func (p *Coord) ScalarMul(p1 *Coord, s *big.Int, curve EdCurve) *Coord {
+	var acc, base Coord
+	acc.SetInfinity()
+	base.Set(p1)
+
+	for i := s.BitLen() - 1; i >= 0; i-- {
+		acc.Double(&acc, curve)
+		if s.Bit(i) == 1 {
+			acc.Add(&acc, &base, curve)
+		}
+	}
+
+	return p.Set(&acc)
+}
+
+// IsOnCurve returns true if p satisfies a*x² + y² = 1 + d*x²*y²
+func (p *Coord) IsOnCurve(curve EdCurve) bool {
+	q := curve.ID.Info().Fr.Modulus()
+
+	var xx, yy, lhs, rhs big.Int
+	xx.Mul(&p.X, &p.X)
+	yy.Mul(&p.Y, &p.Y)
+	lhs.Mul(&xx, &curve.A).Add(&lhs, &yy).Mod(&lhs, q)
+	rhs.Mul(&xx, &yy).Mul(&rhs, &curve.D).Add(&rhs, big.NewInt(1)).Mod(&rhs, q)
+
+	return lhs.Cmp(&rhs) == 0
+}
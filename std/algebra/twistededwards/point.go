@@ -17,8 +17,12 @@ limitations under the License.
 package twistededwards
 
 import (
+	"errors"
 	"math/big"
+	"sync"
 
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/hint"
 	"github.com/consensys/gnark/frontend"
 )
 
@@ -171,3 +175,213 @@ func (p *Point) DoubleBaseScalarMul(api frontend.API, p1, p2 *Point, s1, s2 fron
 
 	return p
 }
+
+// ScalarMulFixedBase computes the scalar multiplication of a constant point,
+// typically the base point of the curve.
+// base: constant point (not a SNARK variable)
+// curve: parameters of the Edwards curve
+// scal: scalar as a SNARK constraint
+// The scalar is processed in windows of 2 bits. Since the base is known at compile
+// time, the multiples [k*4ⁱ]base for k in {0,1,2,3} are precomputed as constants,
+// so that each window costs one 2-bit lookup and one addition, without doublings.
+func (p *Point) ScalarMulFixedBase(api frontend.API, base Coord, scalar frontend.Variable, curve EdCurve) *Point {
+
+	// first unpack the scalar
+	b := api.ToBinary(scalar)
+	if len(b)%2 == 1 {
+		b = append(b, 0)
+	}
+
+	table := fixedBaseTable(base, len(b)/2, curve)
+
+	res := Point{}
+	tmp := Point{}
+
+	res.X = api.Lookup2(b[0], b[1], table[0][0].X, table[0][1].X, table[0][2].X, table[0][3].X)
+	res.Y = api.Lookup2(b[0], b[1], table[0][0].Y, table[0][1].Y, table[0][2].Y, table[0][3].Y)
+	for i := 1; i < len(table); i++ {
+		tmp.X = api.Lookup2(b[2*i], b[2*i+1], table[i][0].X, table[i][1].X, table[i][2].X, table[i][3].X)
+		tmp.Y = api.Lookup2(b[2*i], b[2*i+1], table[i][0].Y, table[i][1].Y, table[i][2].Y, table[i][3].Y)
+		res.Add(api, &res, &tmp, curve)
+	}
+
+	p.X = res.X
+	p.Y = res.Y
+
+	return p
+}
+
+type fixedBaseTableKey struct {
+	id        ecc.ID
+	x, y      string
+	nbWindows int
+}
+
+// fixedBaseTables caches the precomputed tables, as the same base (the curve
+// base point) is typically used many times in a circuit
+var fixedBaseTables sync.Map // fixedBaseTableKey -> [][4]Coord
+
+// fixedBaseTable returns, for each window i, the points [k*4ⁱ]base for k in {0,1,2,3}
+func fixedBaseTable(base Coord, nbWindows int, curve EdCurve) [][4]Coord {
+	key := fixedBaseTableKey{id: curve.ID, x: base.X.String(), y: base.Y.String(), nbWindows: nbWindows}
+	if table, ok := fixedBaseTables.Load(key); ok {
+		return table.([][4]Coord)
+	}
+
+	table := make([][4]Coord, nbWindows)
+	var b Coord
+	b.Set(&base)
+	for i := 0; i < nbWindows; i++ {
+		if i != 0 {
+			b.Double(&b, curve).Double(&b, curve)
+		}
+		table[i][0].SetInfinity()
+		table[i][1].Set(&b)
+		table[i][2].Double(&b, curve)
+		table[i][3].Add(&table[i][2], &b, curve)
+	}
+
+	fixedBaseTables.Store(key, table)
+	return table
+}
+
+// decompressionHint computes the x coordinate of a point from its y coordinate
+// and the sign bit (1 if x is lexicographically largest, 0 otherwise).
+var decompressionHint = hint.NewStaticHint(func(curveID ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	curve, err := NewEdCurve(curveID)
+	if err != nil {
+		return err
+	}
+	x, err := computeX(inputs[0], curve)
+	if err != nil {
+		return err
+	}
+	if isLexicographicallyLargest(x, curveID) != (inputs[1].Sign() != 0) {
+		x.Neg(x).Mod(x, curveID.Info().Fr.Modulus())
+	}
+	res[0].Set(x)
+	return nil
+}, 2, 1)
+
+// signHint returns 1 if the input is lexicographically largest, 0 otherwise
+var signHint = hint.NewStaticHint(func(curveID ecc.ID, inputs []*big.Int, res []*big.Int) error {
+	if isLexicographicallyLargest(inputs[0], curveID) {
+		res[0].SetUint64(1)
+	} else {
+		res[0].SetUint64(0)
+	}
+	return nil
+}, 1, 1)
+
+func init() {
+	hint.Register(decompressionHint)
+	hint.Register(signHint)
+}
+
+// Decompress sets p to the point of the curve with coordinate y, and whose x
+// coordinate is lexicographically largest if signBit is 1. This matches the
+// compressed encoding of gnark-crypto (https://tools.ietf.org/html/rfc8032#section-3.1),
+// see ParseCompressed to obtain y and signBit from the serialized bytes.
+//
+// The x coordinate is computed with a hint, the circuit only checks that the point
+// is on the curve and that the sign of x matches signBit.
+func (p *Point) Decompress(api frontend.API, y, signBit frontend.Variable, curve EdCurve) *Point {
+	api.AssertIsBoolean(signBit)
+
+	res, err := api.NewHint(decompressionHint, y, signBit)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	p.X = res[0]
+	p.Y = y
+	p.MustBeOnCurve(api, curve)
+	p.mustHaveSign(api, signBit, curve)
+
+	return p
+}
+
+// Compress returns the y coordinate of p and the sign bit of its x coordinate (1
+// if x is lexicographically largest, 0 otherwise), such that Decompress(y, signBit)
+// returns p.
+func (p *Point) Compress(api frontend.API, curve EdCurve) (y, signBit frontend.Variable) {
+	res, err := api.NewHint(signHint, p.X)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	signBit = res[0]
+	api.AssertIsBoolean(signBit)
+	p.mustHaveSign(api, signBit, curve)
+
+	return p.Y, signBit
+}
+
+// mustHaveSign asserts that p.X is lexicographically largest iff signBit == 1,
+// that is p.X > (q-1)/2 where q is the modulus of the SNARK field.
+func (p *Point) mustHaveSign(api frontend.API, signBit frontend.Variable, curve EdCurve) {
+	var bound big.Int
+	bound.Sub(curve.ID.Info().Fr.Modulus(), big.NewInt(1)).Rsh(&bound, 1)
+
+	// if signBit == 1, then x > (q-1)/2 ⟺ -x ⩽ (q-1)/2 with x != 0
+	x := api.Select(signBit, api.Neg(p.X), p.X)
+	api.AssertIsLessOrEqual(x, bound)
+	api.AssertIsEqual(api.Mul(signBit, api.IsZero(p.X)), 0)
+}
+
+// ParseCompressed returns the y coordinate and the sign bit of a point serialized
+// in compressed form by gnark-crypto (PointAffine.Bytes(), as used in the
+// public keys and signatures of the EdDSA package).
+func ParseCompressed(id ecc.ID, buf []byte) (y big.Int, signBit uint, err error) {
+	size := id.Info().Fr.Bytes
+	if len(buf) < size {
+		return y, 0, errors.New("buffer too short")
+	}
+
+	// the y coordinate is encoded in little endian, the sign bit is the msb
+	yBytes := make([]byte, size)
+	for i := 0; i < size; i++ {
+		yBytes[i] = buf[size-1-i]
+	}
+	signBit = uint(yBytes[0] >> 7)
+	yBytes[0] &= 0x7f
+
+	y.SetBytes(yBytes)
+	if y.Cmp(id.Info().Fr.Modulus()) >= 0 {
+		return y, 0, errors.New("invalid y coordinate")
+	}
+
+	return y, signBit, nil
+}
+
+// computeX returns x such that (x, y) is on the curve
+// x² = (1 - y²) / (a - d*y²)
+func computeX(y *big.Int, curve EdCurve) (*big.Int, error) {
+	q := curve.ID.Info().Fr.Modulus()
+
+	var num, den big.Int
+	num.Mul(y, y).Mod(&num, q)
+	den.Mul(&num, &curve.D)
+	num.Sub(big.NewInt(1), &num)
+	den.Sub(&curve.A, &den).Mod(&den, q)
+	if den.ModInverse(&den, q) == nil {
+		return nil, errors.New("invalid y coordinate")
+	}
+	num.Mul(&num, &den).Mod(&num, q)
+
+	x := new(big.Int)
+	if x.ModSqrt(&num, q) == nil {
+		return nil, errors.New("y is not the coordinate of a point on the curve")
+	}
+	return x, nil
+}
+
+// isLexicographicallyLargest returns true if x > (q-1)/2 where q is the
+// modulus of the SNARK field
+func isLexicographicallyLargest(x *big.Int, id ecc.ID) bool {
+	var bound big.Int
+	bound.Sub(id.Info().Fr.Modulus(), big.NewInt(1)).Rsh(&bound, 1)
+	return x.Cmp(&bound) > 0
+}
@@ -818,3 +818,154 @@ func TestNeg(t *testing.T) {
 	assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
 
 }
+
+type scalarMulFixedBase struct {
+	E Point
+	S frontend.Variable
+}
+
+func (circuit *scalarMulFixedBase) Define(api frontend.API) error {
+
+	// get edwards curve params
+	params, err := NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+
+	var resFixed, resGeneric, p Point
+	resFixed.ScalarMulFixedBase(api, params.Base, circuit.S, params)
+
+	p.X = params.Base.X
+	p.Y = params.Base.Y
+	resGeneric.ScalarMul(api, &p, circuit.S, params)
+
+	api.AssertIsEqual(resFixed.X, circuit.E.X)
+	api.AssertIsEqual(resFixed.Y, circuit.E.Y)
+	api.AssertIsEqual(resFixed.X, resGeneric.X)
+	api.AssertIsEqual(resFixed.Y, resGeneric.Y)
+
+	return nil
+}
+
+func TestScalarMulFixedBase(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	for _, id := range ecc.Implemented() {
+
+		params, err := NewEdCurve(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// generate witness data
+		var s big.Int
+		s.SetString("1234567890123456789012345678901234567890123456789012345678901234567", 10)
+		s.Mod(&s, &params.Order)
+
+		var expected Coord
+		expected.ScalarMul(&params.Base, &s, params)
+		assert.True(expected.IsOnCurve(params))
+
+		var circuit, witness scalarMulFixedBase
+		witness.S = s
+		witness.E.X = expected.X
+		witness.E.Y = expected.Y
+
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(id))
+
+		// scalar 0 gives the neutral element
+		witness.S = 0
+		witness.E.X = 0
+		witness.E.Y = 1
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(id))
+	}
+}
+
+type compression struct {
+	P       Point
+	Y, Sign frontend.Variable
+}
+
+func (circuit *compression) Define(api frontend.API) error {
+
+	// get edwards curve params
+	params, err := NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+
+	var p Point
+	p.Decompress(api, circuit.Y, circuit.Sign, params)
+	api.AssertIsEqual(p.X, circuit.P.X)
+	api.AssertIsEqual(p.Y, circuit.P.Y)
+
+	y, sign := circuit.P.Compress(api, params)
+	api.AssertIsEqual(y, circuit.Y)
+	api.AssertIsEqual(sign, circuit.Sign)
+
+	return nil
+}
+
+func TestCompression(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	for _, id := range ecc.Implemented() {
+
+		params, err := NewEdCurve(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// generate witness data, for both signs of x
+		var p Coord
+		p.ScalarMul(&params.Base, big.NewInt(928323002), params)
+		for i := 0; i < 2; i++ {
+			if i == 1 {
+				p.Neg(&p, params)
+			}
+
+			var circuit, witness compression
+			witness.P.X = p.X
+			witness.P.Y = p.Y
+			witness.Y = p.Y
+			if isLexicographicallyLargest(&p.X, id) {
+				witness.Sign = 1
+			} else {
+				witness.Sign = 0
+			}
+			assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(id))
+
+			// wrong sign
+			witness.Sign = 1 - witness.Sign.(int)
+			assert.SolvingFailed(&circuit, &witness, test.WithCurves(id))
+		}
+	}
+}
+
+func TestParseCompressed(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	params, err := NewEdCurve(ecc.BN254)
+	assert.NoError(err)
+
+	var p tbn254.PointAffine
+	p.X.SetBigInt(&params.Base.X)
+	p.Y.SetBigInt(&params.Base.Y)
+	for i := 0; i < 2; i++ {
+		if i == 1 {
+			p.Neg(&p)
+		}
+		buf := p.Bytes()
+
+		y, sign, err := ParseCompressed(ecc.BN254, buf[:])
+		assert.NoError(err)
+		assert.Equal(0, y.Cmp(p.Y.ToBigIntRegular(new(big.Int))))
+		assert.Equal(p.X.LexicographicallyLargest(), sign == 1)
+	}
+
+	_, _, err = ParseCompressed(ecc.BN254, make([]byte, 31))
+	assert.Error(err)
+}
@@ -0,0 +1,254 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kzg provides a ZKP-circuit function to verify BLS12_377 KZG opening proofs inside a BW6_761 circuit.
+package kzg
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/fields_bls12377"
+	"github.com/consensys/gnark/std/algebra/sw_bls12377"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// nbChallengeBits is the size in bits of the folding coefficients used in
+// VerifyMultiPoints.
+const nbChallengeBits = 128
+
+// Digest commitment of a polynomial.
+type Digest = sw_bls12377.G1Affine
+
+// VK verification key (subset of the SRS)
+type VK struct {
+	G1 sw_bls12377.G1Affine    // G₁
+	G2 [2]sw_bls12377.G2Affine // [G₂, [α]G₂]
+}
+
+// OpeningProof KZG proof for opening at a single point.
+//
+// The claimed value is not part of the proof, it is given separately to Verify
+// so that it can be computed in the circuit.
+type OpeningProof struct {
+	// H quotient polynomial (f - f(z))/(x-z)
+	H sw_bls12377.G1Affine
+}
+
+// Assign a value to self (witness assignment)
+func (vk *VK) Assign(srs *kzg.SRS) {
+	vk.G1.Assign(&srs.G1[0])
+	vk.G2[0].Assign(&srs.G2[0])
+	vk.G2[1].Assign(&srs.G2[1])
+}
+
+// Assign a value to self (witness assignment)
+func (proof *OpeningProof) Assign(p *kzg.OpeningProof) {
+	proof.H.Assign(&p.H)
+}
+
+// Verify verifies a KZG opening proof at a single point: it checks that the
+// polynomial committed in commitment evaluates to value at point.
+func Verify(api frontend.API, commitment Digest, point, value frontend.Variable, proof OpeningProof, vk VK) error {
+
+	// [f(α) - f(a) + aH(α)]G₁
+	p := foldedDigest(api, commitment, point, value, proof, vk)
+
+	pairingCheck(api, p, newG1Point(api, proof.H), vk)
+
+	return nil
+}
+
+// VerifyMultiPoints batch verifies a list of opening proofs at different points,
+// using only two Miller loops and one final exponentiation.
+//
+// The folding coefficients are derived in the circuit from a MiMC hash of the
+// digests, points, values and proofs.
+func VerifyMultiPoints(api frontend.API, digests []Digest, points, values []frontend.Variable, proofs []OpeningProof, vk VK) error {
+
+	// check consistancy nb proofs vs nb digests
+	if len(digests) != len(proofs) || len(digests) != len(points) || len(digests) != len(values) {
+		return errors.New("number of digests, points, values and proofs must be the same")
+	}
+	if len(digests) == 0 {
+		return errors.New("at least one opening proof is expected")
+	}
+
+	// if only one digest, call Verify
+	if len(digests) == 1 {
+		return Verify(api, digests[0], points[0], values[0], proofs[0], vk)
+	}
+
+	// derive the folding coefficients λᵢ, λ₀ = 1
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(digests); i++ {
+		h.Write(digests[i].X, digests[i].Y, proofs[i].H.X, proofs[i].H.Y, points[i], values[i])
+	}
+	seed := h.Sum()
+
+	// ∑ᵢλᵢ[fᵢ(α) - fᵢ(aᵢ) + aᵢHᵢ(α)]G₁ and ∑ᵢλᵢ[Hᵢ(α)]G₁
+	foldedDigests := foldedDigest(api, digests[0], points[0], values[0], proofs[0], vk)
+	foldedQuotients := newG1Point(api, proofs[0].H)
+	for i := 1; i < len(digests); i++ {
+		h.Write(seed, i)
+		lambda := truncate(api, h.Sum())
+
+		tmp := foldedDigest(api, digests[i], points[i], values[i], proofs[i], vk)
+		foldedDigests = add(api, foldedDigests, scalarMul(api, tmp, lambda, vk))
+
+		tmp = newG1Point(api, proofs[i].H)
+		foldedQuotients = add(api, foldedQuotients, scalarMul(api, tmp, lambda, vk))
+	}
+
+	pairingCheck(api, foldedDigests, foldedQuotients, vk)
+
+	return nil
+}
+
+// g1Point is a point of G₁ which may be the point at infinity. The point at
+// infinity is assigned (0, 0), as in gnark-crypto, which is not on the curve.
+//
+// The sw_bls12377 arithmetic uses incomplete formulas which do not handle the
+// point at infinity; openings at 0, of value 0 or of constant polynomials are
+// valid, and go through it.
+type g1Point struct {
+	sw_bls12377.G1Affine
+	isInfinity frontend.Variable // 1 for the point at infinity, 0 otherwise
+}
+
+// newG1Point returns p, which is the point at infinity if it is (0, 0)
+func newG1Point(api frontend.API, p sw_bls12377.G1Affine) g1Point {
+	return g1Point{
+		G1Affine:   p,
+		isInfinity: api.And(api.IsZero(p.X), api.IsZero(p.Y)),
+	}
+}
+
+// add returns p + q, for any p and q.
+func add(api frontend.API, p, q g1Point) g1Point {
+	xEqual := api.IsZero(api.Sub(q.X, p.X))
+	yEqual := api.IsZero(api.Sub(q.Y, p.Y))
+
+	// λ = (q.y-p.y)/(q.x-p.x), or 3p.x²/2p.y if p = q (p.y is not 0 in G₁).
+	// If one of the points is at infinity the result does not depend on λ,
+	// divide by 1 instead of possibly 0.
+	num := api.Select(xEqual, api.Mul(p.X, p.X, 3), api.Sub(q.Y, p.Y))
+	den := api.Select(xEqual, api.Mul(p.Y, 2), api.Sub(q.X, p.X))
+	den = api.Select(api.Or(p.isInfinity, q.isInfinity), 1, den)
+	lambda := api.DivUnchecked(num, den)
+
+	var res g1Point
+
+	// xr = λ²-p.x-q.x, yr = λ(p.x-xr)-p.y
+	res.X = api.Sub(api.Mul(lambda, lambda), p.X, q.X)
+	res.Y = api.Sub(api.Mul(lambda, api.Sub(p.X, res.X)), p.Y)
+
+	// p + (-p) is the point at infinity
+	res.isInfinity = api.And(xEqual, api.Sub(1, yEqual))
+
+	// O + q = q, p + O = p
+	res.G1Affine.Select(api, q.isInfinity, p.G1Affine, res.G1Affine)
+	res.isInfinity = api.Select(q.isInfinity, p.isInfinity, res.isInfinity)
+	res.G1Affine.Select(api, p.isInfinity, q.G1Affine, res.G1Affine)
+	res.isInfinity = api.Select(p.isInfinity, q.isInfinity, res.isInfinity)
+
+	return res
+}
+
+// scalarMul returns [s]p, for any s and p.
+//
+// If s is 0 or p is the point at infinity, the result is the point at infinity
+// and sw_bls12377's scalar multiplication is computed on [1]G₁ instead.
+func scalarMul(api frontend.API, p g1Point, s frontend.Variable, vk VK) g1Point {
+	sIsZero := api.IsZero(s)
+
+	var res g1Point
+	res.G1Affine.Select(api, p.isInfinity, vk.G1, p.G1Affine)
+	res.G1Affine.ScalarMul(api, res.G1Affine, api.Select(sIsZero, 1, s))
+	res.isInfinity = api.Or(sIsZero, p.isInfinity)
+
+	return res
+}
+
+// foldedDigest returns [f(α) - f(a) + aH(α)]G₁
+func foldedDigest(api frontend.API, commitment Digest, point, value frontend.Variable, proof OpeningProof, vk VK) g1Point {
+
+	// [-f(a)]G₁
+	res := scalarMul(api, g1Point{G1Affine: vk.G1, isInfinity: 0}, value, vk)
+	res.Neg(api, res.G1Affine)
+
+	// [f(α) - f(a)]G₁
+	res = add(api, res, newG1Point(api, commitment))
+
+	// [aH(α)]G₁
+	res = add(api, res, scalarMul(api, newG1Point(api, proof.H), point, vk))
+
+	return res
+}
+
+// pairingCheck asserts that e(p, G₂).e(-q, [α]G₂) == 1, that is p = [α]q
+func pairingCheck(api frontend.API, p, q g1Point, vk VK) {
+	pairingInfo := pairingContext(api)
+
+	// p and q are either both at infinity or none is. The Miller loop does not
+	// handle the point at infinity: in this case check e(G₁, G₂).e(-G₁, G₂) == 1
+	// instead.
+	api.AssertIsEqual(p.isInfinity, q.isInfinity)
+
+	var P, negQ sw_bls12377.G1Affine
+	P.Select(api, p.isInfinity, vk.G1, p.G1Affine)
+	negQ.Select(api, q.isInfinity, vk.G1, q.G1Affine)
+	negQ.Neg(api, negQ)
+
+	var alphaG2 sw_bls12377.G2Affine
+	alphaG2.X.A0 = api.Select(q.isInfinity, vk.G2[0].X.A0, vk.G2[1].X.A0)
+	alphaG2.X.A1 = api.Select(q.isInfinity, vk.G2[0].X.A1, vk.G2[1].X.A1)
+	alphaG2.Y.A0 = api.Select(q.isInfinity, vk.G2[0].Y.A0, vk.G2[1].Y.A0)
+	alphaG2.Y.A1 = api.Select(q.isInfinity, vk.G2[0].Y.A1, vk.G2[1].Y.A1)
+
+	var resMillerLoop, tmp fields_bls12377.E12
+	sw_bls12377.MillerLoop(api, P, vk.G2[0], &resMillerLoop, pairingInfo)
+	sw_bls12377.MillerLoop(api, negQ, alphaG2, &tmp, pairingInfo)
+	resMillerLoop.Mul(api, resMillerLoop, tmp, pairingInfo.Extension)
+
+	// performs the final expo
+	var resPairing, one fields_bls12377.E12
+	resPairing.FinalExponentiation(api, resMillerLoop, pairingInfo.AteLoop, pairingInfo.Extension)
+
+	one.SetOne(api)
+	resPairing.MustBeEqual(api, one)
+}
+
+// truncate returns the nbChallengeBits low bits of v, so that the result is a
+// valid scalar for the BLS12_377 curve.
+func truncate(api frontend.API, v frontend.Variable) frontend.Variable {
+	bits := api.ToBinary(v)
+	return api.FromBinary(bits[:nbChallengeBits]...)
+}
+
+// pairingContext returns the BLS12_377 pairing data
+func pairingContext(api frontend.API) sw_bls12377.PairingContext {
+	ateLoop := uint64(9586122913090633729)
+	ext := fields_bls12377.GetBLS12377ExtensionFp12(api)
+	pairingInfo := sw_bls12377.PairingContext{AteLoop: ateLoop, Extension: ext}
+	pairingInfo.BTwistCoeff.A0 = 0
+	pairingInfo.BTwistCoeff.A1 = "155198655607781456406391640216936120121836107652948796323930557600032281009004493664981332883744016074664192874906"
+	return pairingInfo
+}
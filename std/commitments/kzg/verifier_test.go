@@ -0,0 +1,178 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kzg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/kzg"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+const polynomialSize = 32
+
+type verifierCircuit struct {
+	Digest       Digest
+	Proof        OpeningProof
+	Point, Value frontend.Variable
+	VK           VK
+}
+
+func (circuit *verifierCircuit) Define(api frontend.API) error {
+	return Verify(api, circuit.Digest, circuit.Point, circuit.Value, circuit.Proof, circuit.VK)
+}
+
+func randomPolynomial() []fr.Element {
+	f := make([]fr.Element, polynomialSize)
+	for i := 0; i < len(f); i++ {
+		f[i].SetRandom()
+	}
+	return f
+}
+
+func newSRS(t *testing.T) *kzg.SRS {
+	srs, err := kzg.NewSRS(polynomialSize, big.NewInt(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srs
+}
+
+// singlePointWitness returns the witness of an opening of f at point
+func singlePointWitness(assert *test.Assert, f []fr.Element, point fr.Element, srs *kzg.SRS) (verifierCircuit, kzg.OpeningProof) {
+	digest, err := kzg.Commit(f, srs)
+	assert.NoError(err)
+
+	proof, err := kzg.Open(f, point, srs)
+	assert.NoError(err)
+
+	// sanity check
+	assert.NoError(kzg.Verify(&digest, &proof, point, srs))
+
+	var witness verifierCircuit
+	witness.Digest.Assign(&digest)
+	witness.Proof.Assign(&proof)
+	witness.Point = point.String()
+	witness.Value = proof.ClaimedValue.String()
+	witness.VK.Assign(srs)
+	return witness, proof
+}
+
+func TestVerifySinglePoint(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	srs := newSRS(t)
+	f := randomPolynomial()
+
+	var point fr.Element
+	point.SetRandom()
+	witness, proof := singlePointWitness(assert, f, point, srs)
+
+	assert.SolvingSucceeded(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// wrong claimed value
+	var wrongValue fr.Element
+	wrongValue.SetOne().Add(&wrongValue, &proof.ClaimedValue)
+	witness.Value = wrongValue.String()
+
+	assert.SolvingFailed(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+func TestVerifySinglePointZero(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	srs := newSRS(t)
+
+	// opening at 0
+	var point fr.Element
+	witness, _ := singlePointWitness(assert, randomPolynomial(), point, srs)
+	assert.SolvingSucceeded(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// opening to 0: f - f(a) vanishes at a
+	f := randomPolynomial()
+	point.SetRandom()
+	_, proof := singlePointWitness(assert, f, point, srs)
+	f[0].Sub(&f[0], &proof.ClaimedValue)
+	witness, proof = singlePointWitness(assert, f, point, srs)
+	assert.True(proof.ClaimedValue.IsZero())
+	assert.SolvingSucceeded(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// constant polynomial: the quotient is the point at infinity
+	f = make([]fr.Element, polynomialSize)
+	f[0].SetUint64(42)
+	witness, proof = singlePointWitness(assert, f, point, srs)
+	assert.True(proof.H.IsInfinity())
+	assert.SolvingSucceeded(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// zero polynomial: the commitment is the point at infinity too
+	f = make([]fr.Element, polynomialSize)
+	witness, _ = singlePointWitness(assert, f, point, srs)
+	assert.SolvingSucceeded(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// claimed value of the zero polynomial not 0
+	witness.Value = 1
+	assert.SolvingFailed(&verifierCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
+
+type multiPointsCircuit struct {
+	Digests       [2]Digest
+	Proofs        [2]OpeningProof
+	Points, Value [2]frontend.Variable
+	VK            VK
+}
+
+func (circuit *multiPointsCircuit) Define(api frontend.API) error {
+	return VerifyMultiPoints(api, circuit.Digests[:], circuit.Points[:], circuit.Value[:], circuit.Proofs[:], circuit.VK)
+}
+
+func TestVerifyMultiPoints(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	srs := newSRS(t)
+
+	var witness multiPointsCircuit
+	var proofs [2]kzg.OpeningProof
+	for i := 0; i < 2; i++ {
+		f := randomPolynomial()
+		digest, err := kzg.Commit(f, srs)
+		assert.NoError(err)
+
+		var point fr.Element
+		point.SetRandom()
+		proofs[i], err = kzg.Open(f, point, srs)
+		assert.NoError(err)
+
+		witness.Digests[i].Assign(&digest)
+		witness.Proofs[i].Assign(&proofs[i])
+		witness.Points[i] = point.String()
+		witness.Value[i] = proofs[i].ClaimedValue.String()
+	}
+	witness.VK.Assign(srs)
+
+	assert.SolvingSucceeded(&multiPointsCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+
+	// wrong claimed value for the second proof
+	var wrongValue fr.Element
+	wrongValue.SetOne().Add(&wrongValue, &proofs[1].ClaimedValue)
+	witness.Value[1] = wrongValue.String()
+
+	assert.SolvingFailed(&multiPointsCircuit{}, &witness, test.WithCurves(ecc.BW6_761))
+}
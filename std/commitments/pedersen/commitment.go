@@ -0,0 +1,62 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pedersen provides ZKP-circuit functions to compute Pedersen vector
+// commitments and Pedersen hashes on the twisted Edwards curves embedded in the
+// SNARK field, together with their native counterparts.
+package pedersen
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+// Commit computes the Pedersen vector commitment
+//
+// [randomness]H + ∑ᵢ[values[i]]Gᵢ
+//
+// where H, G₀, G₁... are the points returned by Generators(curve, len(values)+1).
+func Commit(api frontend.API, curve twistededwards.EdCurve, values []frontend.Variable, randomness frontend.Variable) twistededwards.Point {
+	bases := Generators(curve, len(values)+1)
+	scalars := append([]frontend.Variable{randomness}, values...)
+
+	// the generators are constants, use the fixed-base scalar multiplication
+	var res, tmp twistededwards.Point
+	res.ScalarMulFixedBase(api, bases[0], scalars[0], curve)
+	for i := 1; i < len(scalars); i++ {
+		tmp.ScalarMulFixedBase(api, bases[i], scalars[i], curve)
+		res.Add(api, &res, &tmp, curve)
+	}
+
+	return res
+}
+
+// NativeCommit computes the Pedersen vector commitment outside of a circuit. The
+// result matches the output of Commit.
+func NativeCommit(curve twistededwards.EdCurve, values []big.Int, randomness big.Int) twistededwards.Coord {
+	bases := Generators(curve, len(values)+1)
+
+	var res, tmp twistededwards.Coord
+	res.ScalarMul(&bases[0], &randomness, curve)
+	for i := 0; i < len(values); i++ {
+		tmp.ScalarMul(&bases[i+1], &values[i], curve)
+		res.Add(&res, &tmp, curve)
+	}
+
+	return res
+}
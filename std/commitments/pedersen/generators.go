@@ -0,0 +1,127 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pedersen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+// domain separation tags used to derive independent sets of generators
+const (
+	domainCommitment = "gnark_pedersen_commitment"
+	domainHash       = "gnark_pedersen_hash"
+)
+
+type generatorKey struct {
+	id     ecc.ID
+	domain string
+	index  int
+}
+
+var generators sync.Map // generatorKey -> twistededwards.Coord
+
+// Generators returns the n generators used by Commit for the given curve.
+//
+// The first returned point is the generator for the blinding factor, the
+// following ones are the generators for the committed values. They are derived
+// deterministically by hashing to the curve, so that nobody knows the discrete
+// logarithm relations between them.
+func Generators(curve twistededwards.EdCurve, n int) []twistededwards.Coord {
+	res := make([]twistededwards.Coord, n)
+	for i := 0; i < n; i++ {
+		res[i] = generator(curve, domainCommitment, i)
+	}
+	return res
+}
+
+// generator returns the index-th generator of the given domain, in the prime
+// order subgroup of the curve.
+func generator(curve twistededwards.EdCurve, domain string, index int) twistededwards.Coord {
+	key := generatorKey{id: curve.ID, domain: domain, index: index}
+	g, ok := generators.Load(key)
+	if !ok {
+		g = hashToCurve(curve, domain, index)
+		generators.Store(key, g)
+	}
+
+	// deep copy so that the cached value can't be modified by the caller
+	var res twistededwards.Coord
+	c := g.(twistededwards.Coord)
+	res.Set(&c)
+	return res
+}
+
+// hashToCurve derives a point in the prime order subgroup of the curve using
+// try-and-increment on the y coordinate:
+//
+// y = sha256(domain || curve || index || counter) mod p
+// x² = (1 - y²) / (a - d*y²)
+//
+// the result is then multiplied by the cofactor.
+func hashToCurve(curve twistededwards.EdCurve, domain string, index int) twistededwards.Coord {
+	p := curve.ID.Info().Fr.Modulus()
+	one := big.NewInt(1)
+
+	var buf [8]byte
+	for counter := uint32(0); ; counter++ {
+		h := sha256.New()
+		h.Write([]byte(domain))
+		h.Write([]byte(curve.ID.String()))
+		binary.BigEndian.PutUint32(buf[:4], uint32(index))
+		binary.BigEndian.PutUint32(buf[4:], counter)
+		h.Write(buf[:])
+
+		var x, y, num, den big.Int
+		y.SetBytes(h.Sum(nil)).Mod(&y, p)
+
+		// num = 1 - y²
+		num.Mul(&y, &y).Mod(&num, p)
+		den.Mul(&num, &curve.D)
+		num.Sub(one, &num).Mod(&num, p)
+
+		// den = a - d*y²
+		den.Sub(&curve.A, &den).Mod(&den, p)
+		if den.Sign() == 0 {
+			continue
+		}
+		den.ModInverse(&den, p)
+		num.Mul(&num, &den).Mod(&num, p)
+		if x.ModSqrt(&num, p) == nil {
+			continue
+		}
+
+		// choose the even root to be canonical
+		if x.Bit(0) == 1 {
+			x.Sub(p, &x)
+		}
+
+		res := twistededwards.Coord{X: x, Y: y}
+		res.ScalarMul(&res, &curve.Cofactor, curve)
+
+		// reject the points of small order
+		if res.X.Sign() == 0 {
+			continue
+		}
+		return res
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pedersen
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+)
+
+// Hash computes the windowed Pedersen hash of a bit string, as specified in
+// the Zcash protocol (https://zips.z.cash/protocol/protocol.pdf, 5.4.1.7).
+//
+// The message is padded with zeroes to a multiple of 3 bits and split into
+// segments of at most nbChunks(curve) chunks of 3 bits. Each chunk (s₀, s₁, s₂)
+// is encoded as (1-2s₂)*(1+s₀+2s₁) and the hash is
+//
+// ∑ᵢ[∑ⱼenc(mᵢⱼ)*2⁴ʲ]Gᵢ
+//
+// where the Gᵢ are fixed generators, independent from the commitment ones.
+// The bits are constrained to be boolean.
+func Hash(api frontend.API, curve twistededwards.EdCurve, bits []frontend.Variable) twistededwards.Point {
+	for i := 0; i < len(bits); i++ {
+		api.AssertIsBoolean(bits[i])
+	}
+	padded := make([]frontend.Variable, len(bits), len(bits)+2)
+	copy(padded, bits)
+	for len(padded)%3 != 0 {
+		padded = append(padded, 0)
+	}
+
+	c := nbChunks(curve)
+	res := twistededwards.Point{X: 0, Y: 1}
+	var tmp twistededwards.Point
+
+	for segment := 0; segment*3*c < len(padded); segment++ {
+		start := segment * 3 * c
+		end := start + 3*c
+		if end > len(padded) {
+			end = len(padded)
+		}
+		tables := chunkTables(curve, segment, (end-start)/3)
+
+		for j, t := range tables {
+			s0, s1, s2 := padded[start+3*j], padded[start+3*j+1], padded[start+3*j+2]
+
+			// [1+s₀+2s₁]Bⱼ
+			tmp.X = api.Lookup2(s0, s1, t[0].X, t[1].X, t[2].X, t[3].X)
+			tmp.Y = api.Lookup2(s0, s1, t[0].Y, t[1].Y, t[2].Y, t[3].Y)
+
+			// conditional negation
+			tmp.X = api.Select(s2, api.Neg(tmp.X), tmp.X)
+
+			res.Add(api, &res, &tmp, curve)
+		}
+	}
+
+	return res
+}
+
+// NativeHash computes the windowed Pedersen hash of a bit string outside of a
+// circuit. The result matches the output of Hash.
+func NativeHash(curve twistededwards.EdCurve, bits []bool) twistededwards.Coord {
+	padded := make([]bool, len(bits), len(bits)+2)
+	copy(padded, bits)
+	for len(padded)%3 != 0 {
+		padded = append(padded, false)
+	}
+
+	c := nbChunks(curve)
+	var res, tmp twistededwards.Coord
+	res.SetInfinity()
+
+	for segment := 0; segment*3*c < len(padded); segment++ {
+		start := segment * 3 * c
+		end := start + 3*c
+		if end > len(padded) {
+			end = len(padded)
+		}
+
+		// ⟨Mᵢ⟩ = ∑ⱼenc(mᵢⱼ)*2⁴ʲ
+		var scalar, enc big.Int
+		for j := (end-start)/3 - 1; j >= 0; j-- {
+			enc.SetUint64(1)
+			if padded[start+3*j] {
+				enc.Add(&enc, big.NewInt(1))
+			}
+			if padded[start+3*j+1] {
+				enc.Add(&enc, big.NewInt(2))
+			}
+			if padded[start+3*j+2] {
+				enc.Neg(&enc)
+			}
+			scalar.Lsh(&scalar, 4).Add(&scalar, &enc)
+		}
+		scalar.Mod(&scalar, &curve.Order)
+
+		g := generator(curve, domainHash, segment)
+		tmp.ScalarMul(&g, &scalar, curve)
+		res.Add(&res, &tmp, curve)
+	}
+
+	return res
+}
+
+// nbChunks returns the maximum number of 3-bits chunks per segment, such that
+// the encoded segment, which lies in [-(r-1)/2, (r-1)/2], is injective mod r.
+func nbChunks(curve twistededwards.EdCurve) int {
+	var bound, max, tmp big.Int
+	bound.Sub(&curve.Order, big.NewInt(1)).Rsh(&bound, 1)
+
+	// max = 4 * ∑ⱼ2⁴ʲ for j < c
+	c := 0
+	for {
+		tmp.Lsh(big.NewInt(4), uint(4*c))
+		tmp.Add(&tmp, &max)
+		if tmp.Cmp(&bound) > 0 {
+			return c
+		}
+		max.Set(&tmp)
+		c++
+	}
+}
+
+// chunkTables returns, for each of the n first chunks of a segment, the
+// multiples [1]Bⱼ, [2]Bⱼ, [3]Bⱼ, [4]Bⱼ of Bⱼ = [2⁴ʲ]Gᵢ.
+func chunkTables(curve twistededwards.EdCurve, segment, n int) [][4]twistededwards.Coord {
+	res := make([][4]twistededwards.Coord, n)
+	base := generator(curve, domainHash, segment)
+	for j := 0; j < n; j++ {
+		if j != 0 {
+			for k := 0; k < 4; k++ {
+				base.Double(&base, curve)
+			}
+		}
+		res[j][0].Set(&base)
+		res[j][1].Double(&res[j][0], curve)
+		res[j][2].Add(&res[j][1], &res[j][0], curve)
+		res[j][3].Add(&res[j][2], &res[j][0], curve)
+	}
+	return res
+}
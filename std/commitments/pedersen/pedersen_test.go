@@ -0,0 +1,182 @@
+/*
+Copyright © 2022 ConsenSys Software Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pedersen
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/test"
+)
+
+var curves = []ecc.ID{ecc.BN254, ecc.BLS12_381, ecc.BLS12_377, ecc.BW6_761, ecc.BLS24_315, ecc.BW6_633}
+
+func TestGenerators(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, id := range curves {
+		curve, err := twistededwards.NewEdCurve(id)
+		assert.NoError(err)
+
+		g := Generators(curve, 3)
+		for i := 0; i < len(g); i++ {
+			assert.True(g[i].IsOnCurve(curve), "generator not on curve")
+
+			// the generators are in the prime order subgroup
+			var tmp twistededwards.Coord
+			tmp.ScalarMul(&g[i], &curve.Order, curve)
+			assert.Equal(0, tmp.X.Sign())
+			assert.Equal(0, tmp.Y.Cmp(big.NewInt(1)))
+
+			for j := 0; j < i; j++ {
+				assert.NotEqual(0, g[i].X.Cmp(&g[j].X), "generators must be distinct")
+			}
+		}
+
+		// deterministic
+		g2 := Generators(curve, 3)
+		for i := 0; i < len(g); i++ {
+			assert.Equal(0, g[i].X.Cmp(&g2[i].X))
+			assert.Equal(0, g[i].Y.Cmp(&g2[i].Y))
+		}
+	}
+}
+
+type commitmentCircuit struct {
+	Values     [3]frontend.Variable
+	Randomness frontend.Variable
+	Commitment twistededwards.Point `gnark:",public"`
+}
+
+func (circuit *commitmentCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+	res := Commit(api, curve, circuit.Values[:], circuit.Randomness)
+	api.AssertIsEqual(res.X, circuit.Commitment.X)
+	api.AssertIsEqual(res.Y, circuit.Commitment.Y)
+	return nil
+}
+
+func TestCommitment(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, id := range curves {
+		curve, err := twistededwards.NewEdCurve(id)
+		assert.NoError(err)
+
+		values := make([]big.Int, 3)
+		var randomness big.Int
+		for i := 0; i < len(values); i++ {
+			values[i].Rand(rand.New(rand.NewSource(int64(i))), &curve.Order) //#nosec G404 -- This is a false positive
+		}
+		randomness.Rand(rand.New(rand.NewSource(42)), &curve.Order) //#nosec G404 -- This is a false positive
+
+		c := NativeCommit(curve, values, randomness)
+		assert.True(c.IsOnCurve(curve), "commitment not on curve")
+
+		var witness commitmentCircuit
+		for i := 0; i < len(values); i++ {
+			witness.Values[i] = values[i]
+		}
+		witness.Randomness = randomness
+		witness.Commitment.X = c.X
+		witness.Commitment.Y = c.Y
+
+		assert.SolvingSucceeded(&commitmentCircuit{}, &witness, test.WithCurves(id))
+
+		// wrong randomness
+		witness.Randomness = new(big.Int).Add(&randomness, big.NewInt(1))
+		assert.SolvingFailed(&commitmentCircuit{}, &witness, test.WithCurves(id))
+	}
+}
+
+type hashCircuit struct {
+	Bits [20]frontend.Variable
+	Hash twistededwards.Point `gnark:",public"`
+}
+
+func (circuit *hashCircuit) Define(api frontend.API) error {
+	curve, err := twistededwards.NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+	res := Hash(api, curve, circuit.Bits[:])
+	api.AssertIsEqual(res.X, circuit.Hash.X)
+	api.AssertIsEqual(res.Y, circuit.Hash.Y)
+	return nil
+}
+
+func TestHash(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	r := rand.New(rand.NewSource(0)) //#nosec G404 -- This is a false positive
+	var bits [20]bool
+	for i := 0; i < len(bits); i++ {
+		bits[i] = r.Intn(2) == 1
+	}
+
+	for _, id := range curves {
+		curve, err := twistededwards.NewEdCurve(id)
+		assert.NoError(err)
+
+		h := NativeHash(curve, bits[:])
+		assert.True(h.IsOnCurve(curve), "hash not on curve")
+
+		var witness hashCircuit
+		for i := 0; i < len(bits); i++ {
+			if bits[i] {
+				witness.Bits[i] = 1
+			} else {
+				witness.Bits[i] = 0
+			}
+		}
+		witness.Hash.X = h.X
+		witness.Hash.Y = h.Y
+
+		assert.SolvingSucceeded(&hashCircuit{}, &witness, test.WithCurves(id))
+
+		// flip a bit
+		witness.Bits[0] = 1 - witness.Bits[0].(int)
+		assert.SolvingFailed(&hashCircuit{}, &witness, test.WithCurves(id))
+	}
+}
+
+func TestHashMultipleSegments(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	curve, err := twistededwards.NewEdCurve(ecc.BN254)
+	assert.NoError(err)
+
+	// a message spanning two segments must differ from the sum of the
+	// hashes computed with a single generator
+	c := nbChunks(curve)
+	bits := make([]bool, 3*c+3)
+	bits[0] = true
+	bits[3*c] = true
+
+	h := NativeHash(curve, bits)
+	assert.True(h.IsOnCurve(curve), "hash not on curve")
+
+	h2 := NativeHash(curve, bits[:3*c])
+	assert.NotEqual(0, h.X.Cmp(&h2.X))
+}
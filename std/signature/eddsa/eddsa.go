@@ -20,6 +20,7 @@ package eddsa
 import (
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
+	"github.com/consensys/gnark/std/hash"
 	"github.com/consensys/gnark/std/hash/mimc"
 )
 
@@ -40,9 +41,24 @@ type Signature struct {
 	S frontend.Variable
 }
 
-// Verify verifies an eddsa signature
+// Verify verifies an eddsa signature, using MiMC to compute the challenge
 // cf https://en.wikipedia.org/wiki/EdDSA
 func Verify(api frontend.API, sig Signature, msg frontend.Variable, pubKey PublicKey) error {
+	hash, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	return VerifyWithHash(api, sig, []frontend.Variable{msg}, pubKey, &hash)
+}
+
+// VerifyWithHash verifies an eddsa signature of a message made of several field
+// elements, using hFunc to compute the challenge H(R, A, M).
+//
+// The signature must have been produced with the native counterpart of hFunc,
+// on the concatenation of the (big endian) field elements of msg, so that
+// H(R, A, M) hashes R.X, R.Y, A.X, A.Y, msg[0], msg[1]... in this order.
+// cf https://en.wikipedia.org/wiki/EdDSA
+func VerifyWithHash(api frontend.API, sig Signature, msg []frontend.Variable, pubKey PublicKey, hFunc hash.Hash) error {
 
 	// compute H(R, A, M), all parameters in data are in Montgomery form
 	data := []frontend.Variable{
@@ -50,26 +66,22 @@ func Verify(api frontend.API, sig Signature, msg frontend.Variable, pubKey Publi
 		sig.R.Y,
 		pubKey.A.X,
 		pubKey.A.Y,
-		msg,
-	}
-
-	hash, err := mimc.NewMiMC(api)
-	if err != nil {
-		return err
 	}
-	hash.Write(data...)
-	hramConstant := hash.Sum()
+	data = append(data, msg...)
 
-	base := twistededwards.Point{}
-	base.X = pubKey.Curve.Base.X
-	base.Y = pubKey.Curve.Base.Y
+	hFunc.Reset()
+	hFunc.Write(data...)
+	hramConstant := hFunc.Sum()
 
 	//[S]G-[H(R,A,M)]*A
+	// G is the curve base point, known at compile time: [S]G uses the fixed-base tables
 	cofactor := pubKey.Curve.Cofactor.Uint64()
 	Q := twistededwards.Point{}
 	_A := twistededwards.Point{}
 	_A.Neg(api, &pubKey.A)
-	Q.DoubleBaseScalarMul(api, &base, &_A, sig.S, hramConstant, pubKey.Curve)
+	Q.ScalarMulFixedBase(api, pubKey.Curve.Base, sig.S, pubKey.Curve)
+	_A.ScalarMul(api, &_A, hramConstant, pubKey.Curve)
+	Q.Add(api, &Q, &_A, pubKey.Curve)
 	Q.MustBeOnCurve(api, pubKey.Curve)
 
 	//[S]G-[H(R,A,M)]*A-R
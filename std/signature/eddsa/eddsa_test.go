@@ -17,6 +17,7 @@ limitations under the License.
 package eddsa
 
 import (
+	gohash "hash"
 	"math/big"
 	"math/rand"
 	"testing"
@@ -28,6 +29,7 @@ import (
 	eddsabls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/twistededwards/eddsa"
 	edwardsbls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards"
 	eddsabls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	edwardsbn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	eddsabn254 "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	edwardsbw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/twistededwards"
@@ -38,6 +40,8 @@ import (
 	"github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/twistededwards"
+	stdhash "github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/test"
 )
 
@@ -252,3 +256,221 @@ func TestEddsa(t *testing.T) {
 
 	}
 }
+
+type eddsaCompressedCircuit struct {
+	PublicKeyY, PublicKeySign frontend.Variable `gnark:",public"`
+	RY, RSign, S              frontend.Variable `gnark:",public"`
+	Message                   frontend.Variable `gnark:",public"`
+}
+
+func (circuit *eddsaCompressedCircuit) Define(api frontend.API) error {
+
+	params, err := twistededwards.NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+
+	// decompress the public key and the signature
+	var pubKey PublicKey
+	pubKey.Curve = params
+	pubKey.A.Decompress(api, circuit.PublicKeyY, circuit.PublicKeySign, params)
+
+	var sig Signature
+	sig.R.Decompress(api, circuit.RY, circuit.RSign, params)
+	sig.S = circuit.S
+
+	// verify the signature in the cs
+	return Verify(api, sig, circuit.Message, pubKey)
+}
+
+func TestEddsaCompressed(t *testing.T) {
+
+	assert := test.NewAssert(t)
+
+	signature.Register(signature.EDDSA_BN254, eddsabn254.GenerateKeyInterfaces)
+
+	// generate parameters for the signatures
+	hFunc := hash.MIMC_BN254.New()
+	r := rand.New(rand.NewSource(0)) //#nosec G404 -- This is a false positive
+	privKey, err := signature.EDDSA_BN254.New(r)
+	assert.NoError(err)
+	pubKey := privKey.Public()
+
+	// pick a message to sign
+	var frMsg big.Int
+	frMsg.SetString("44717650746155748460101257525078853138837311576962212923649547644148297035978", 10)
+	msgBin := frMsg.Bytes()
+
+	sig, err := privKey.Sign(msgBin[:], hFunc)
+	assert.NoError(err)
+
+	// the public key and signature R are consumed in the compressed form
+	// produced by gnark-crypto
+	var witness eddsaCompressedCircuit
+	witness.Message = frMsg
+
+	pbY, pbSign, err := twistededwards.ParseCompressed(ecc.BN254, pubKey.Bytes())
+	assert.NoError(err)
+	witness.PublicKeyY = pbY
+	witness.PublicKeySign = pbSign
+
+	rY, rSign, err := twistededwards.ParseCompressed(ecc.BN254, sig)
+	assert.NoError(err)
+	witness.RY = rY
+	witness.RSign = rSign
+	witness.S = sig[32:]
+
+	assert.SolvingSucceeded(&eddsaCompressedCircuit{}, &witness, test.WithCurves(ecc.BN254))
+
+	// flipping the sign of R must make the verification fail
+	witness.RSign = 1 - rSign
+	assert.SolvingFailed(&eddsaCompressedCircuit{}, &witness, test.WithCurves(ecc.BN254))
+}
+
+// hornerHash is a toy hash function used to test that the challenge hash is
+// pluggable: H(d₀, d₁, ...) = ∑ᵢdᵢ*7ⁿ⁻¹⁻ⁱ. It must not be used outside of tests.
+type hornerHash struct {
+	api  frontend.API
+	data []frontend.Variable
+}
+
+func (h *hornerHash) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+func (h *hornerHash) Reset() {
+	h.data = nil
+}
+
+func (h *hornerHash) Sum() frontend.Variable {
+	var res frontend.Variable = 0
+	for _, d := range h.data {
+		res = h.api.Add(h.api.Mul(res, 7), d)
+	}
+	return res
+}
+
+// nativeHornerHash is the native counterpart of hornerHash, on BN254
+type nativeHornerHash struct {
+	data []byte
+}
+
+func (h *nativeHornerHash) Write(p []byte) (int, error) {
+	h.data = append(h.data, p...)
+	return len(p), nil
+}
+
+func (h *nativeHornerHash) Sum(b []byte) []byte {
+	var res, d, seven fr.Element
+	seven.SetUint64(7)
+	for i := 0; i < len(h.data); i += fr.Bytes {
+		d.SetBytes(h.data[i : i+fr.Bytes])
+		res.Mul(&res, &seven).Add(&res, &d)
+	}
+	bytes := res.Bytes()
+	return append(b, bytes[:]...)
+}
+
+func (h *nativeHornerHash) Reset()         { h.data = nil }
+func (h *nativeHornerHash) Size() int      { return fr.Bytes }
+func (h *nativeHornerHash) BlockSize() int { return fr.Bytes }
+
+type eddsaMultiCircuit struct {
+	PublicKey PublicKey            `gnark:",public"`
+	Signature Signature            `gnark:",public"`
+	Message   [3]frontend.Variable `gnark:",public"`
+	useMiMC   bool
+}
+
+func (circuit *eddsaMultiCircuit) Define(api frontend.API) error {
+
+	params, err := twistededwards.NewEdCurve(api.Curve())
+	if err != nil {
+		return err
+	}
+	circuit.PublicKey.Curve = params
+
+	var hFunc stdhash.Hash
+	if circuit.useMiMC {
+		h, err := mimc.NewMiMC(api)
+		if err != nil {
+			return err
+		}
+		hFunc = &h
+	} else {
+		hFunc = &hornerHash{api: api}
+	}
+
+	return VerifyWithHash(api, circuit.Signature, circuit.Message[:], circuit.PublicKey, hFunc)
+}
+
+// signNative signs a message made of several field elements, serialized as the
+// concatenation of their big endian representations
+func signNative(t *testing.T, privKey signature.Signer, msg []fr.Element, hFunc gohash.Hash) []byte {
+	var msgBin []byte
+	for i := 0; i < len(msg); i++ {
+		b := msg[i].Bytes()
+		msgBin = append(msgBin, b[:]...)
+	}
+	sig, err := privKey.Sign(msgBin, hFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSig, err := privKey.Public().Verify(sig, msgBin, hFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkSig {
+		t.Fatal("Unexpected failed signature verification")
+	}
+	return sig
+}
+
+func TestEddsaWithHash(t *testing.T) {
+
+	signature.Register(signature.EDDSA_BN254, eddsabn254.GenerateKeyInterfaces)
+
+	r := rand.New(rand.NewSource(0)) //#nosec G404 -- This is a false positive
+	privKey, err := signature.EDDSA_BN254.New(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := privKey.Public()
+
+	var msg [3]fr.Element
+	for i := 0; i < len(msg); i++ {
+		msg[i].SetUint64(uint64(42 + i))
+	}
+
+	for _, useMiMC := range []bool{true, false} {
+		// the compiled circuit depends on the hash function, use a fresh cache
+		assert := test.NewAssert(t)
+
+		var hFunc gohash.Hash
+		if useMiMC {
+			hFunc = hash.MIMC_BN254.New()
+		} else {
+			hFunc = &nativeHornerHash{}
+		}
+		sig := signNative(t, privKey, msg[:], hFunc)
+
+		witness := eddsaMultiCircuit{useMiMC: useMiMC}
+		for i := 0; i < len(msg); i++ {
+			witness.Message[i] = msg[i]
+		}
+		pubkeyAx, pubkeyAy := parsePoint(ecc.BN254, pubKey.Bytes())
+		witness.PublicKey.A.X = pubkeyAx
+		witness.PublicKey.A.Y = pubkeyAy
+		sigRx, sigRy, sigS := parseSignature(ecc.BN254, sig)
+		witness.Signature.R.X = sigRx
+		witness.Signature.R.Y = sigRy
+		witness.Signature.S = sigS
+
+		circuit := eddsaMultiCircuit{useMiMC: useMiMC}
+		assert.SolvingSucceeded(&circuit, &witness, test.WithCurves(ecc.BN254))
+
+		// tamper with the last element of the message
+		witness.Message[2] = 0
+		assert.SolvingFailed(&circuit, &witness, test.WithCurves(ecc.BN254))
+	}
+}
@@ -36,7 +36,8 @@ const srsCachedSize = (1 << 14) + 3
 // NewKZGSRS uses ccs nb variables and nb constraints to initialize a kzg srs
 // for sizes < 2¹⁵, returns a pre-computed cached SRS
 //
-// /!\ warning /!\: this method is here for convenience only: in production, a SRS generated through MPC should be used.
+// /!\ warning /!\: this method is here for convenience only: in production, a SRS generated through MPC should be used
+// (see plonk.NewSRS, plonk.ReadPtau and plonk.ReadPPoTResponse).
 func NewKZGSRS(ccs frontend.CompiledConstraintSystem) (kzg.SRS, error) {
 
 	nbConstraints := ccs.GetNbConstraints()